@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ReadAliases reads the aliases of station names, such as the Chinese, Malay and Tamil
+// names, from the given io.Reader, and returns the station names by alias.
+// It assumes the format being:
+/*
+Station Name,Alias
+Dhoby Ghaut,多美歌
+Changi Airport,Lapangan Terbang Changi
+*/
+func ReadAliases(r io.Reader) (map[string]string, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := make(map[string]string)
+
+	for _, record := range records {
+		if len(record) != 2 {
+			return nil, fmt.Errorf("record length not 2: %v", record)
+		}
+		name, alias := record[0], record[1]
+		if normalizeName(alias) == "" {
+			return nil, fmt.Errorf("empty alias for %s", name)
+		}
+		if other, ok := final[alias]; ok && other != name {
+			return nil, fmt.Errorf("alias %s for both %s and %s", alias, other, name)
+		}
+		final[alias] = name
+	}
+
+	return final, nil
+}
+
+// validateAliases checks that every alias refers to a known station name, and does not
+// match the name of another station
+func validateAliases(stations []Station, aliases map[string]string) error {
+	names := make(map[string]string)
+	for _, s := range stations {
+		names[normalizeName(s.name)] = s.name
+	}
+	for alias, name := range aliases {
+		if _, ok := names[normalizeName(name)]; !ok {
+			return fmt.Errorf("alias %s references unknown station %s", alias, name)
+		}
+		if other, ok := names[normalizeName(alias)]; ok && normalizeName(other) != normalizeName(name) {
+			return fmt.Errorf("alias %s for %s matches station %s", alias, name, other)
+		}
+	}
+	return nil
+}
+
+// normalizeName is a helper function to compare station names regardless of case,
+// whitespace and punctuation, eg. "Harbour Front" and "harbourfront" for HarbourFront
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// normalizeAliases is a helper function to key the station names by normalised alias
+func normalizeAliases(aliases map[string]string) map[string]string {
+	normalized := make(map[string]string)
+	for alias, name := range aliases {
+		normalized[normalizeName(alias)] = name
+	}
+	return normalized
+}
+
+// maxSuggestions is the number of station names suggested for an unknown station
+const maxSuggestions = 5
+
+// suggestStations is a helper function to rank the station names similar to the input
+// by the edit distance of their normalised names or aliases, for suggestions when the
+// input is not found. Names farther than a third of the input length are left out.
+func suggestStations(stations []Station, aliases map[string]string, input string, k int) []string {
+	key := []rune(normalizeName(input))
+	if len(key) == 0 {
+		return []string{}
+	}
+	maxDistance := len(key) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	// the closest distance of each station name by itself or its aliases
+	distances := make(map[string]int)
+	consider := func(candidate, name string) {
+		d := editDistance(key, []rune(normalizeName(candidate)))
+		if best, ok := distances[name]; d <= maxDistance && (!ok || d < best) {
+			distances[name] = d
+		}
+	}
+	for _, s := range stations {
+		consider(s.name, s.name)
+	}
+	present := make(map[string]bool)
+	for _, s := range stations {
+		present[s.name] = true
+	}
+	for alias, name := range aliases {
+		if present[name] {
+			consider(alias, name)
+		}
+	}
+
+	names := []string{}
+	for name := range distances {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distances[names[i]] != distances[names[j]] {
+			return distances[names[i]] < distances[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > k {
+		names = names[:k]
+	}
+	return names
+}
+
+// editDistance is a helper function to compute the Levenshtein distance between runes
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+		}
+		prev, current = current, prev
+	}
+	return prev[len(b)]
+}
+
+// min3 is a helper function to find the minimum of three integers
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Suggest returns up to 5 station names similar to the input ranked by edit distance,
+// for "did you mean" suggestions when the input matches no StationID, station name or
+// alias of all Stations regardless of their opening dates. It returns nil if the input
+// matches any station.
+func (n *Navigator) Suggest(input string) []string {
+	return n.graphByStops().suggest(input)
+}
+
+// SuggestAt is like Suggest among the Stations open at the given time, so a Station not
+// yet open is never suggested. If the input matches such a Station, it returns no
+// suggestions but the earliest opening date of the Stations matched instead.
+func (n *Navigator) SuggestAt(input string, t time.Time) ([]string, time.Time) {
+	t = t.In(networkLocation)
+	c := n.graphAt(t)
+	if _, _, err := searchStations(c.stations, c.aliases, input); err == nil {
+		return nil, time.Time{}
+	}
+
+	ids, _, err := searchStations(c.allStations, c.aliases, input)
+	if err != nil {
+		return suggestStations(c.stations, c.aliases, input, maxSuggestions), time.Time{}
+	}
+	var opening time.Time
+	for _, s := range c.allStations {
+		if !t.Before(s.openingDate) || !containsStationID(ids, s.id) {
+			continue
+		}
+		if opening.IsZero() || s.openingDate.Before(opening) {
+			opening = s.openingDate
+		}
+	}
+	return nil, opening
+}
+
+// suggest is a helper function to suggest station names among the Stations of the cached
+// Graph, or nil if the input matches any of them
+func (c cachedGraph) suggest(input string) []string {
+	if _, _, err := searchStations(c.stations, c.aliases, input); err == nil {
+		return nil
+	}
+	return suggestStations(c.stations, c.aliases, input, maxSuggestions)
+}
+
+// containsStationID is a helper function to check if the StationID is in the list
+func containsStationID(ids []StationID, id StationID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
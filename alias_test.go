@@ -0,0 +1,150 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadAliases(t *testing.T) {
+	aliases, err := ReadAliases(strings.NewReader("Station Name,Alias\nDhoby Ghaut,多美歌\nChangi Airport,Lapangan Terbang Changi\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"多美歌": "Dhoby Ghaut", "Lapangan Terbang Changi": "Changi Airport"}
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, aliases)
+	}
+
+	for _, input := range []string{
+		"Station Name,Alias\nDhoby Ghaut\n",
+		"Station Name,Alias\nDhoby Ghaut, \n",
+		"Station Name,Alias\nDhoby Ghaut,DG\nBugis,DG\n",
+	} {
+		if _, err := ReadAliases(strings.NewReader(input)); err == nil {
+			t.Errorf("expect error on %q", input)
+		}
+	}
+}
+
+func TestValidateAliases(t *testing.T) {
+	stations := []Station{
+		Station{id: StationID{"NS", 24}, name: "Dhoby Ghaut"},
+		Station{id: StationID{"EW", 12}, name: "Bugis"},
+	}
+	if err := validateAliases(stations, map[string]string{"多美歌": "Dhoby Ghaut"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := validateAliases(stations, map[string]string{"乌节": "Orchard"}); err == nil {
+		t.Errorf("expect error on alias of unknown station")
+	}
+	if err := validateAliases(stations, map[string]string{"bugis": "Dhoby Ghaut"}); err == nil {
+		t.Errorf("expect error on alias matching another station")
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	for input, expected := range map[string]string{
+		"HarbourFront":  "harbourfront",
+		"Harbour Front": "harbourfront",
+		" one-north ":   "onenorth",
+		"多美歌":           "多美歌",
+	} {
+		if actual := normalizeName(input); actual != expected {
+			t.Errorf("%q expected: %q, actual: %q", input, expected, actual)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, testCase := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"bugis", "", 5},
+		{"dhobygaut", "dhobyghaut", 1},
+		{"kitten", "sitting", 3},
+		{"多美哥", "多美歌", 1},
+	} {
+		if actual := editDistance([]rune(testCase.a), []rune(testCase.b)); actual != testCase.expected {
+			t.Errorf("%s-%s expected: %d, actual: %d", testCase.a, testCase.b, testCase.expected, actual)
+		}
+	}
+}
+
+func TestNavigateAliases(t *testing.T) {
+	n := defaultNavigator()
+	expected, err := n.NavigateByStops("HarbourFront", "Dhoby Ghaut", NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][2]string{
+		{"harbourfront", "dhoby ghaut"},
+		{"Harbour Front", "DHOBY GHAUT"},
+		{"港湾", "多美歌"},
+		{"ஹார்பர்ஃபிரண்ட்", "தோபி காட்"},
+	} {
+		actual, err := n.NavigateByStops(pair[0], pair[1], NavigateOptions{})
+		if err != nil {
+			t.Errorf("%s-%s unexpected error: %s", pair[0], pair[1], err)
+		} else if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s-%s expected: %v, actual: %v", pair[0], pair[1], expected, actual)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	n := defaultNavigator()
+	for _, testCase := range []struct {
+		input    string
+		expected []string
+	}{
+		{"Dhoby Gaut", []string{"Dhoby Ghaut"}},
+		{"Habourfront", []string{"HarbourFront"}},
+		{"Tampnes", []string{"Tampines"}},
+		{"樟宜机", []string{"Changi Airport"}},
+		{"Orchard", nil},
+		{"Atlantis", []string{}},
+	} {
+		if actual := n.Suggest(testCase.input); !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("%s expected: %v, actual: %v", testCase.input, testCase.expected, actual)
+		}
+	}
+}
+
+func TestSuggestAt(t *testing.T) {
+	n := defaultNavigator()
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	for _, testCase := range []struct {
+		input    string
+		expected []string
+		opening  string
+	}{
+		{"Orchard", nil, ""},
+		{"Dhoby Gaut", []string{"Dhoby Ghaut"}, ""},
+		// TE13 is not open in 2020, so it is not suggested for a typo, nor for itself
+		{"Orchard Boulevrd", []string{}, ""},
+		{"Orchard Boulevard", nil, "2021-12-31"},
+		{"TE13", nil, "2021-12-31"},
+	} {
+		suggested, opening := n.SuggestAt(testCase.input, travelTime)
+		if !reflect.DeepEqual(suggested, testCase.expected) {
+			t.Errorf("%s expected: %v, actual: %v", testCase.input, testCase.expected, suggested)
+		}
+		actual := ""
+		if !opening.IsZero() {
+			actual = opening.Format("2006-01-02")
+		}
+		if actual != testCase.opening {
+			t.Errorf("%s expected opening: %q, actual: %q", testCase.input, testCase.opening, actual)
+		}
+	}
+
+	// the same typo is suggested once the station opens
+	travelTime, _ = time.ParseInLocation("2006-01-02T15:04", "2022-01-03T08:00", networkLocation)
+	if suggested, _ := n.SuggestAt("Orchard Boulevrd", travelTime); !reflect.DeepEqual(suggested, []string{"Orchard Boulevard"}) {
+		t.Errorf("expected: [Orchard Boulevard], actual: %v", suggested)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+// Heuristic estimates the remaining weight from vertex v to destination for A* search.
+// It must never overestimate and must be consistent, ie. the estimate at a vertex is at
+// most the weight of any edge from it plus the estimate at the other end.
+type Heuristic func(v, dest VertexID) Weight
+
+// AStar finds the path with minimum weight from source to destination in a Graph like
+// Dijkstra, but expands the vertices with smaller weight plus heuristic estimate first.
+// It returns error when
+// 1) source or destination does not exist in the Graph;
+// 2) source and destination are the same;
+// 3) no path is found.
+func (g *Graph) AStar(src, dest VertexID, heuristic Heuristic) (Path, error) {
+	return g.TimeDependentAStar(src, dest, staticWeight, heuristic)
+}
+
+// TimeDependentAStar is AStar where the weight of each edge is evaluated by the weight
+// function like TimeDependentDijkstra. The heuristic must be a lower bound of the weight
+// at any time.
+func (g *Graph) TimeDependentAStar(src, dest VertexID, weight EdgeWeightFunc, heuristic Heuristic) (Path, error) {
+	if err := validate(g, src, dest); err != nil {
+		return Path{}, err
+	}
+	d := g.index[dest]
+	found, arrival := false, Weight(0)
+	parent := g.settle(src, 0, weight, func(v int) Weight { return heuristic(g.ids[v], dest) }, func(current int, currentWeight Weight) bool {
+		found, arrival = current == d, currentWeight
+		return !found
+	})
+	if !found {
+		return Path{}, ErrorPathNotFound
+	}
+	return Path{Stops: g.backtrack(d, parent), Weight: arrival}, nil
+}
+
+// Landmarks returns the ALT (A*, landmarks and triangle inequality) Heuristic of the
+// Graph. It picks k landmarks far apart from each other and precomputes their weights to
+// every vertex, where the weight function is evaluated at the start as the lower bound.
+// The estimate from v to destination is the largest difference between their weights
+// to any landmark, which assumes the edges are linked in both directions.
+func (g *Graph) Landmarks(k int, weight EdgeWeightFunc) Heuristic {
+	lowerBound := func(u, v VertexID, w Weight, _ Weight) (Weight, bool) {
+		return weight(u, v, w, 0)
+	}
+
+	// the weight from a landmark to each vertex by number, -1 when not reachable
+	distances := func(landmark int) []Weight {
+		dist := make([]Weight, len(g.ids))
+		for i := range dist {
+			dist[i] = -1
+		}
+		g.settle(g.ids[landmark], 0, lowerBound, nil, func(current int, currentWeight Weight) bool {
+			dist[current] = currentWeight
+			return true
+		})
+		return dist
+	}
+
+	// pick the first vertex, then each time the vertex farthest from the picked ones
+	landmarks := [][]Weight{}
+	nearest := make([]Weight, len(g.ids))
+	for i := range nearest {
+		nearest[i] = -1
+	}
+	next := 0
+	for len(landmarks) < k && next < len(g.ids) {
+		dist := distances(next)
+		landmarks = append(landmarks, dist)
+		farthest := -1
+		for v, w := range dist {
+			if w >= 0 && (nearest[v] < 0 || w < nearest[v]) {
+				nearest[v] = w
+			}
+			if nearest[v] > 0 && (farthest < 0 || nearest[v] > nearest[farthest]) {
+				farthest = v
+			}
+		}
+		if farthest < 0 {
+			break
+		}
+		next = farthest
+	}
+
+	return func(v, dest VertexID) Weight {
+		i, ok := g.index[v]
+		if !ok {
+			return 0
+		}
+		j, ok := g.index[dest]
+		if !ok {
+			return 0
+		}
+		estimate := Weight(0)
+		for _, dist := range landmarks {
+			if dist[i] < 0 || dist[j] < 0 {
+				continue
+			}
+			diff := dist[j] - dist[i]
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > estimate {
+				estimate = diff
+			}
+		}
+		return estimate
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAStar(t *testing.T) {
+	zero := func(_, _ VertexID) Weight { return 0 }
+	for _, testCase := range weightedTestCases {
+		for _, heuristic := range []Heuristic{zero, testCase.g.Landmarks(2, staticWeight)} {
+			actual, err := testCase.g.AStar(testCase.src, testCase.dest, heuristic)
+			if err != nil {
+				t.Errorf("expected: %v, actual error: %s", testCase.expected[0], err)
+			}
+			if !reflect.DeepEqual(actual, testCase.expected[0]) {
+				t.Errorf("expected: %v, actual: %v", testCase.expected[0], actual)
+			}
+		}
+	}
+}
+
+func TestLandmarks(t *testing.T) {
+	// 1 - 2 - 3 - 4 with weights 1, 2, 3, and 5 not connected, where an end of the line
+	// is picked as the second landmark so the estimates are exact
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(2), IntVertex(3), 2).
+		LinkBoth(IntVertex(3), IntVertex(4), 3).
+		Add(IntVertex(5))
+	h := g.Landmarks(2, staticWeight)
+
+	for _, testCase := range []struct {
+		v        VertexID
+		dest     VertexID
+		expected Weight
+	}{
+		{v: 1, dest: 4, expected: 6},
+		{v: 2, dest: 3, expected: 2},
+		{v: 4, dest: 2, expected: 5},
+		{v: 1, dest: 5, expected: 0},
+		{v: 9, dest: 1, expected: 0},
+	} {
+		if actual := h(testCase.v, testCase.dest); actual != testCase.expected {
+			t.Errorf("%v to %v expected: %d, actual: %d", testCase.v, testCase.dest, testCase.expected, actual)
+		}
+	}
+}
+
+func TestAStarAllPairs(t *testing.T) {
+	network := defaultNetwork()
+	g := buildGraph(network.stations, network.segments, defaultSchedule().travelCost(periodPeak))
+	h := g.Landmarks(4, staticWeight)
+
+	for _, src := range network.stations {
+		for _, dest := range network.stations {
+			if src.id == dest.id {
+				continue
+			}
+			expected, _ := g.Dijkstra(src.id, dest.id)
+			actual, err := g.AStar(src.id, dest.id, h)
+			if err != nil || actual.Weight != expected.Weight {
+				t.Fatalf("%s to %s expected weight: %d, actual: %v, %v", src.id, dest.id, expected.Weight, actual, err)
+			}
+			if w, ok := g.Evaluate(actual.Stops, staticWeight); !ok || w != actual.Weight {
+				t.Fatalf("%s to %s path weight not match: %v", src.id, dest.id, actual)
+			}
+		}
+	}
+}
+
+func BenchmarkGraphAStar(b *testing.B) {
+	var network = defaultNetwork()
+	var g = buildGraph(network.stations, network.segments, TravelCostByStop{})
+	var h = g.Landmarks(4, staticWeight)
+	var source = StationID{line: "CC", number: 19}
+	var destination = StationID{line: "DT", number: 15}
+
+	for i := 0; i < b.N; i++ {
+		g.AStar(source, destination, h)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorAvoidNotFound is returned by Navigator when an avoided station or line does not exist.
+var ErrorAvoidNotFound = errors.New("avoided station or line not found")
+
+// ErrorSourceAvoided is returned by Navigator when all the source stations are avoided.
+var ErrorSourceAvoided = errors.New("source is avoided")
+
+// ErrorDestinationAvoided is returned by Navigator when all the destination stations are avoided.
+var ErrorDestinationAvoided = errors.New("destination is avoided")
+
+// ErrorAvoidDisconnected is returned by Navigator when a path exists between source and
+// destination, but not after removing the avoided stations and lines.
+var ErrorAvoidDisconnected = errors.New("avoided stations or lines disconnect source from destination")
+
+// avoided resolves the stations and lines to avoid into StationIDs among all Stations of
+// the network. Stations can be given by either StationID or name, and lines by line code.
+func (c cachedGraph) avoided(opts NavigateOptions) (map[StationID]bool, error) {
+	result := make(map[StationID]bool)
+	for _, input := range opts.AvoidStations {
+		ids, _, err := searchStations(c.allStations, c.aliases, input)
+		if err != nil {
+			return nil, ErrorAvoidNotFound
+		}
+		for _, id := range ids {
+			result[id] = true
+		}
+	}
+	for _, line := range opts.AvoidLines {
+		line = strings.ToUpper(line)
+		found := false
+		for _, s := range c.allStations {
+			if s.id.line == line {
+				result[s.id] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrorAvoidNotFound
+		}
+	}
+	return result, nil
+}
+
+// avoiding returns a new cachedGraph without the avoided Stations. The Segments touching
+// them are removed as well, so no route passes through them. It keeps the Heuristic of
+// the cached Graph, which stays a lower bound as removing Stations never shortens a route.
+func (c cachedGraph) avoiding(avoided map[StationID]bool) cachedGraph {
+	stations := []Station{}
+	for _, s := range c.stations {
+		if !avoided[s.id] {
+			stations = append(stations, s)
+		}
+	}
+	segments := []Segment{}
+	for _, seg := range c.segments {
+		if !avoided[seg.from] && !avoided[seg.to] {
+			segments = append(segments, seg)
+		}
+	}
+	c.stations = stations
+	c.segments = segments
+	c.hops = lineHops(stations, segments)
+	c.graph = buildGraph(stations, segments, c.cost)
+	return c
+}
+
+// withoutAvoided is a helper function to remove the avoided StationIDs from the list
+func withoutAvoided(ids []StationID, avoided map[StationID]bool) []StationID {
+	result := []StationID{}
+	for _, id := range ids {
+		if !avoided[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
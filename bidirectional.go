@@ -0,0 +1,109 @@
+package main
+
+import "container/heap"
+
+// BidirectionalDijkstra finds the path with minimum weight from source to destination in
+// a Graph by searching forward from source and backward from destination in turns, until
+// the searches meet. It assumes the edges are linked in both directions.
+// It returns error when
+// 1) source or destination does not exist in the Graph;
+// 2) source and destination are the same;
+// 3) no path is found.
+func (g *Graph) BidirectionalDijkstra(src, dest VertexID) (Path, error) {
+	return g.bidirectionalDijkstra(src, dest, staticWeight)
+}
+
+// bidirectionalDijkstra is a helper function which runs BidirectionalDijkstra with the
+// weight function evaluated at the start, as the backward search does not know the
+// accumulated weight of edges from source.
+func (g *Graph) bidirectionalDijkstra(src, dest VertexID, weight EdgeWeightFunc) (Path, error) {
+	if err := validate(g, src, dest); err != nil {
+		return Path{}, err
+	}
+
+	// the search in each direction, where the backward one takes edges in reverse
+	type search struct {
+		parent  []int
+		visited []bool
+		reached []bool
+		dist    []Weight
+		queue   *priorityQueue
+		reverse bool
+	}
+	newSearch := func(from int, reverse bool) *search {
+		s := &search{
+			parent:  newParents(len(g.ids)),
+			visited: make([]bool, len(g.ids)),
+			reached: make([]bool, len(g.ids)),
+			dist:    make([]Weight, len(g.ids)),
+			queue:   &priorityQueue{queueItem{vertex: from, weight: 0}},
+			reverse: reverse,
+		}
+		s.reached[from] = true
+		return s
+	}
+	forward, backward := newSearch(g.index[src], false), newSearch(g.index[dest], true)
+
+	// the best path found so far meets at vertex meet with total weight best
+	meet, best := -1, Weight(0)
+	// top gives the weight of the nearest vertex not visited yet in a search
+	top := func(s *search) (Weight, bool) {
+		for s.queue.Len() > 0 {
+			item := (*s.queue)[0]
+			if !s.visited[item.vertex] {
+				return item.weight, true
+			}
+			heap.Pop(s.queue)
+		}
+		return 0, false
+	}
+
+	for {
+		f, okForward := top(forward)
+		b, okBackward := top(backward)
+		if !okForward || !okBackward || (meet >= 0 && f+b >= best) {
+			break
+		}
+
+		// expand the search with the nearer frontier, forward on ties
+		s, other := forward, backward
+		if b < f {
+			s, other = backward, forward
+		}
+		current := heap.Pop(s.queue).(queueItem).vertex
+		s.visited[current] = true
+
+		for _, e := range g.adjacency[current] {
+			if s.visited[e.to] {
+				continue
+			}
+			u, v := g.ids[current], g.ids[e.to]
+			if s.reverse {
+				u, v = v, u
+			}
+			w, ok := weight(u, v, e.weight, 0)
+			if !ok {
+				continue
+			}
+			alt := s.dist[current] + w
+			if !s.reached[e.to] || alt < s.dist[e.to] {
+				s.reached[e.to], s.dist[e.to] = true, alt
+				s.parent[e.to] = current
+				heap.Push(s.queue, queueItem{vertex: e.to, weight: alt})
+				if other.reached[e.to] && (meet < 0 || alt+other.dist[e.to] < best) {
+					meet, best = e.to, alt+other.dist[e.to]
+				}
+			}
+		}
+	}
+	if meet < 0 {
+		return Path{}, ErrorPathNotFound
+	}
+
+	// join the forward path to the meeting vertex and the backward path from it
+	stops := g.backtrack(meet, forward.parent)
+	for current := backward.parent[meet]; current >= 0; current = backward.parent[current] {
+		stops = append(stops, *g.Vertices[g.ids[current]])
+	}
+	return Path{Stops: stops, Weight: best}, nil
+}
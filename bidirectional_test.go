@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBidirectionalDijkstra(t *testing.T) {
+	for _, testCase := range weightedTestCases {
+		actual, err := testCase.g.BidirectionalDijkstra(testCase.src, testCase.dest)
+		if err != nil {
+			t.Errorf("expected: %v, actual error: %s", testCase.expected[0], err)
+		}
+		if !reflect.DeepEqual(actual, testCase.expected[0]) {
+			t.Errorf("expected: %v, actual: %v", testCase.expected[0], actual)
+		}
+	}
+
+	g := NewGraph().LinkBoth(IntVertex(1), IntVertex(2), 1).Add(IntVertex(3))
+	for _, testCase := range []struct {
+		src      VertexID
+		dest     VertexID
+		expected error
+	}{
+		{src: 9, dest: 1, expected: ErrorSourceNotFound},
+		{src: 1, dest: 9, expected: ErrorDestinationNotFound},
+		{src: 1, dest: 1, expected: ErrorSourceDestinationSame},
+		{src: 1, dest: 3, expected: ErrorPathNotFound},
+	} {
+		if _, err := g.BidirectionalDijkstra(testCase.src, testCase.dest); err != testCase.expected {
+			t.Errorf("expected error: %v, actual: %v", testCase.expected, err)
+		}
+	}
+}
+
+func TestBidirectionalDijkstraAllPairs(t *testing.T) {
+	network := defaultNetwork()
+	g := buildGraph(network.stations, network.segments, defaultSchedule().travelCost(periodPeak))
+
+	for _, src := range network.stations {
+		for _, dest := range network.stations {
+			if src.id == dest.id {
+				continue
+			}
+			expected, _ := g.Dijkstra(src.id, dest.id)
+			actual, err := g.BidirectionalDijkstra(src.id, dest.id)
+			if err != nil || actual.Weight != expected.Weight {
+				t.Fatalf("%s to %s expected weight: %d, actual: %v, %v", src.id, dest.id, expected.Weight, actual, err)
+			}
+			if w, ok := g.Evaluate(actual.Stops, staticWeight); !ok || w != actual.Weight {
+				t.Fatalf("%s to %s path weight not match: %v", src.id, dest.id, actual)
+			}
+		}
+	}
+}
+
+func BenchmarkGraphBidirectionalDijkstra(b *testing.B) {
+	var network = defaultNetwork()
+	var g = buildGraph(network.stations, network.segments, TravelCostByStop{})
+	var source = StationID{line: "CC", number: 19}
+	var destination = StationID{line: "DT", number: 15}
+
+	for i := 0; i < b.N; i++ {
+		g.BidirectionalDijkstra(source, destination)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// commands are the subcommands run by name in place of the web server, building the
+// Navigator with the given options
+var commands = map[string]func(args []string, stdout io.Writer, opts []NavigatorOption) error{
+	"matrix":      runMatrix,
+	"gtfs-export": runGTFSExport,
+	"validate":    runValidate,
+}
+
+// runMatrix is the matrix subcommand, which writes the origin-destination matrix at the
+// given time of travel to stdout, eg.
+//
+//	mrt matrix -time 2020-11-09T08:00 -format csv -sources "Bishan,Orchard"
+func runMatrix(args []string, stdout io.Writer, opts []NavigatorOption) error {
+	fs := flag.NewFlagSet("matrix", flag.ContinueOnError)
+	timeStr := fs.String("time", "", "time of travel in format YYYY-MM-DDThh:mm in Singapore time or RFC 3339")
+	format := fs.String("format", "csv", "output format, csv or json")
+	sources := fs.String("sources", "", "comma-separated station names or codes, all stations if empty")
+	destinations := fs.String("destinations", "", "comma-separated station names or codes, all stations if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := parseTravelTime(*timeStr)
+	if err != nil {
+		return err
+	}
+
+	n, err := NewNavigator(opts...)
+	if err != nil {
+		return err
+	}
+	cells, err := n.Matrix(splitList(*sources), splitList(*destinations), t)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "csv":
+		return WriteMatrixCSV(stdout, cells)
+	case "json":
+		return WriteMatrixJSON(stdout, cells)
+	default:
+		return errors.New("unknown format")
+	}
+}
+
+// runGTFSExport is the gtfs-export subcommand, which writes the network operating at the
+// given time as a GTFS feed in zip format to the output file or stdout, eg.
+//
+//	mrt gtfs-export -time 2020-11-09T08:00 -o gtfs.zip
+func runGTFSExport(args []string, stdout io.Writer, opts []NavigatorOption) error {
+	fs := flag.NewFlagSet("gtfs-export", flag.ContinueOnError)
+	timeStr := fs.String("time", "", "time of the network in format YYYY-MM-DDThh:mm in Singapore time or RFC 3339, now if empty")
+	output := fs.String("o", "", "output zip file, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t := time.Now()
+	if *timeStr != "" {
+		var err error
+		if t, err = parseTravelTime(*timeStr); err != nil {
+			return err
+		}
+	}
+
+	n, err := NewNavigator(opts...)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return n.WriteGTFS(stdout, t)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := n.WriteGTFS(f, t); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runValidate is the validate subcommand, which checks the network data and writes the
+// ValidationReport in json format to stdout, and fails if any error is found, eg.
+//
+//	mrt -data ./data validate -time 2020-11-09T08:00
+func runValidate(args []string, stdout io.Writer, opts []NavigatorOption) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	timeStr := fs.String("time", "", "time to check future openings in format YYYY-MM-DDThh:mm in Singapore time or RFC 3339, now if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t := time.Now()
+	if *timeStr != "" {
+		var err error
+		if t, err = parseTravelTime(*timeStr); err != nil {
+			return err
+		}
+	}
+
+	report := Validate(newNavigatorConfig(opts).source, t)
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if report.Errors > 0 {
+		return fmt.Errorf("validation failed with %d errors", report.Errors)
+	}
+	return nil
+}
+
+// splitList is a helper function to split a comma-separated list, ignoring empty items
+func splitList(s string) []string {
+	result := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Coordinates is a geographic location in degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// valid checks that the latitude and the longitude are within their ranges in degrees
+func (c Coordinates) valid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// earthRadiusMeters is the mean radius of the Earth
+const earthRadiusMeters = 6371000
+
+// walkingMetersPerMinute is the walking speed, about 4.8 km/h
+const walkingMetersPerMinute = 80
+
+// maxWalkingMeters is the farthest distance to walk to or from a Station when navigating
+// from or to Coordinates
+const maxWalkingMeters = 2000
+
+// distanceMeters returns the great-circle distance between two Coordinates by the
+// haversine formula
+func distanceMeters(a, b Coordinates) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	lat1, lat2 := toRadians(a.Latitude), toRadians(b.Latitude)
+	dLat, dLon := lat2-lat1, toRadians(b.Longitude-a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// walkingMinutes estimates the minutes to walk the distance, rounded up
+func walkingMinutes(meters float64) Weight {
+	return Weight(math.Ceil(meters / walkingMetersPerMinute))
+}
+
+// ReadCoordinates reads the coordinates of stations from the given io.Reader.
+// It assumes the format being:
+/*
+Station Code,Latitude,Longitude
+NS1,1.3332,103.7423
+*/
+func ReadCoordinates(r io.Reader) (map[StationID]Coordinates, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := make(map[StationID]Coordinates)
+
+	for _, record := range records {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("record length not 3: %v", record)
+		}
+		id, err := NewStationID(record[0])
+		if err != nil {
+			return nil, err
+		}
+		lat, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		lon, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		c := Coordinates{Latitude: lat, Longitude: lon}
+		if !c.valid() {
+			return nil, fmt.Errorf("coordinates out of range: %v", record)
+		}
+		if _, ok := final[id]; ok {
+			return nil, fmt.Errorf("duplicate coordinates for station %s", id)
+		}
+		final[id] = c
+	}
+
+	return final, nil
+}
+
+// validateCoordinates checks that every coordinates belong to a station in the station map.
+func validateCoordinates(stations []Station, coordinates map[StationID]Coordinates) error {
+	known := make(map[StationID]bool)
+	for _, s := range stations {
+		known[s.id] = true
+	}
+	for id := range coordinates {
+		if !known[id] {
+			return fmt.Errorf("coordinates for unknown station %s", id)
+		}
+	}
+	return nil
+}
+
+// NearbyStation is a station near some Coordinates, with the StationIDs of its name
+type NearbyStation struct {
+	Name    string
+	IDs     []StationID
+	Meters  float64
+	Walking Weight
+}
+
+// NearestStations returns up to k stations nearest to the given Coordinates ordered by
+// distance, where the interchange Stations of the same name are counted once. Stations
+// without coordinates are not considered.
+func (n *Navigator) NearestStations(lat, lon float64, k int) []NearbyStation {
+	n.mu.Lock()
+	stations, coordinates := n.allStations, n.coordinates
+	n.mu.Unlock()
+
+	return nearbyStations(stations, coordinates, Coordinates{Latitude: lat, Longitude: lon}, k)
+}
+
+// nearbyStations is a helper function to find up to k station names among the Stations
+// nearest to the Coordinates
+func nearbyStations(stations []Station, coordinates map[StationID]Coordinates, at Coordinates, k int) []NearbyStation {
+	byName := make(map[string]*NearbyStation)
+	result := []*NearbyStation{}
+	for _, s := range stations {
+		c, ok := coordinates[s.id]
+		if !ok {
+			continue
+		}
+		meters := distanceMeters(at, c)
+		nearby, ok := byName[s.name]
+		if !ok {
+			nearby = &NearbyStation{Name: s.name, Meters: meters}
+			byName[s.name] = nearby
+			result = append(result, nearby)
+		}
+		nearby.IDs = append(nearby.IDs, s.id)
+		if meters < nearby.Meters {
+			nearby.Meters = meters
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Meters < result[j].Meters })
+
+	final := []NearbyStation{}
+	for _, nearby := range result {
+		if len(final) >= k {
+			break
+		}
+		nearby.Walking = walkingMinutes(nearby.Meters)
+		final = append(final, *nearby)
+	}
+	return final
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCoordinates(t *testing.T) {
+	fileContent := "Station Code,Latitude,Longitude\n" +
+		"NS22,1.3040,103.8318\n" +
+		"TE14,1.3040,103.8318\n"
+	expected := map[StationID]Coordinates{
+		StationID{line: "NS", number: 22}: Coordinates{Latitude: 1.3040, Longitude: 103.8318},
+		StationID{line: "TE", number: 14}: Coordinates{Latitude: 1.3040, Longitude: 103.8318},
+	}
+	actual, err := ReadCoordinates(strings.NewReader(fileContent))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestReadCoordinatesError(t *testing.T) {
+	for _, fileContent := range []string{
+		"",
+		"Station Code,Latitude,Longitude\nNS22,1.3040\n",
+		"Station Code,Latitude,Longitude\nNS22,north,103.8318\n",
+		"Station Code,Latitude,Longitude\nNS22,91,103.8318\n",
+		"Station Code,Latitude,Longitude\nNS22,1.3040,103.8318\nNS22,1.3040,103.8318\n",
+	} {
+		_, err := ReadCoordinates(strings.NewReader(fileContent))
+		if err == nil {
+			t.Errorf("expect error for input: %q", fileContent)
+		}
+	}
+}
+
+func TestDistanceMeters(t *testing.T) {
+	// Orchard to Dhoby Ghaut
+	orchard := Coordinates{Latitude: 1.3040, Longitude: 103.8318}
+	dhobyGhaut := Coordinates{Latitude: 1.2990, Longitude: 103.8455}
+	if actual := distanceMeters(orchard, dhobyGhaut); math.Abs(actual-1621) > 1 {
+		t.Errorf("expected about 1621 meters, actual: %f", actual)
+	}
+	if actual := distanceMeters(orchard, orchard); actual != 0 {
+		t.Errorf("expected 0 meters, actual: %f", actual)
+	}
+	if actual := walkingMinutes(1621); actual != 21 {
+		t.Errorf("expected 21 minutes, actual: %d", actual)
+	}
+}
+
+func TestNearestStations(t *testing.T) {
+	nearby := defaultNavigator().NearestStations(1.3048, 103.8318, 3)
+
+	expected := []string{"Orchard", "Orchard Boulevard", "Somerset"}
+	actual := []string{}
+	for _, s := range nearby {
+		actual = append(actual, s.Name)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	expectedIDs := []StationID{StationID{line: "NS", number: 22}, StationID{line: "TE", number: 14}}
+	if !reflect.DeepEqual(expectedIDs, nearby[0].IDs) || nearby[0].Walking != 2 {
+		t.Errorf("expected Orchard %v within 2 minutes walk, actual: %v", expectedIDs, nearby[0])
+	}
+}
+
+func TestNavigateCoordinates(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	nearOrchard := &Coordinates{Latitude: 1.3048, Longitude: 103.8318}
+	nearChangiAirport := &Coordinates{Latitude: 1.3600, Longitude: 103.9900}
+
+	routes, err := defaultNavigator().NavigateByTime("", "", travelTime, NavigateOptions{
+		SourceCoordinates:      nearOrchard,
+		DestinationCoordinates: nearChangiAirport,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"NS22", "NS23", "NS24", "NS25", "EW13", "EW12", "EW11", "EW10", "EW9", "EW8", "EW7", "EW6", "EW5", "EW4", "CG0", "CG1", "CG2"}
+	if actual := pathToStringSlice(routes[0].Stops); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected path: %v, actual: %v", expected, actual)
+	}
+	// 2 minutes walking to Orchard, 162 minutes by train departing 2 minutes later, and 5 minutes walking from Changi Airport
+	if routes[0].Weight != 169 || routes[0].WalkToSource != 2 || routes[0].WalkFromDestination != 5 {
+		t.Errorf("expected weight 169 with walking 2 and 5, actual: %d with walking %d and %d", routes[0].Weight, routes[0].WalkToSource, routes[0].WalkFromDestination)
+	}
+
+	byStops, err := defaultNavigator().NavigateByStops("", "Changi Airport", NavigateOptions{SourceCoordinates: nearOrchard})
+	if err != nil || byStops[0].Weight != 16 || byStops[0].WalkToSource != 2 {
+		t.Errorf("expected 16 stops with walking 2, actual: %v, %v", byStops, err)
+	}
+
+	farAway := &Coordinates{Latitude: 1.0, Longitude: 103.0}
+	if _, err := defaultNavigator().NavigateByTime("", "Changi Airport", travelTime, NavigateOptions{SourceCoordinates: farAway}); err != ErrorSourceNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorSourceNotFound, err)
+	}
+	if _, err := defaultNavigator().NavigateByTime("Orchard", "", travelTime, NavigateOptions{DestinationCoordinates: farAway}); err != ErrorDestinationNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorDestinationNotFound, err)
+	}
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
+	"container/heap"
 	"errors"
-	"math"
+	"fmt"
 	"sort"
 )
 
@@ -19,9 +20,20 @@ type Weight int
 
 // Graph contains all the Vertex references by a map accessed by ID.
 // It also stores the edges for each Vertex, which is a map of weights.
+// Vertices are numbered in the order they are added and traversed in that order,
+// so searches are deterministic.
 type Graph struct {
-	Vertices map[VertexID]*Vertex
-	Edges    map[VertexID]map[VertexID]Weight
+	Vertices  map[VertexID]*Vertex
+	Edges     map[VertexID]map[VertexID]Weight
+	ids       []VertexID
+	index     map[VertexID]int
+	adjacency [][]edge
+}
+
+// edge is an entry of the adjacency list pointing to a vertex by its number
+type edge struct {
+	to     int
+	weight Weight
 }
 
 // NewGraph creates an empty Graph, and returns its reference.
@@ -29,6 +41,7 @@ func NewGraph() *Graph {
 	return &Graph{
 		Vertices: make(map[VertexID]*Vertex),
 		Edges:    make(map[VertexID]map[VertexID]Weight),
+		index:    make(map[VertexID]int),
 	}
 }
 
@@ -36,9 +49,12 @@ func NewGraph() *Graph {
 func (g *Graph) Add(v Vertex) *Graph {
 	// add or replace reference to station
 	g.Vertices[v.ID()] = &v
-	// initialize edges map if not done so
+	// initialize edges map and number the vertex if not done so
 	if g.Edges[v.ID()] == nil {
 		g.Edges[v.ID()] = make(map[VertexID]Weight)
+		g.index[v.ID()] = len(g.ids)
+		g.ids = append(g.ids, v.ID())
+		g.adjacency = append(g.adjacency, nil)
 	}
 	return g
 }
@@ -47,11 +63,28 @@ func (g *Graph) Add(v Vertex) *Graph {
 func (g *Graph) LinkBoth(v, u Vertex, w Weight) *Graph {
 	g.Add(u)
 	g.Add(v)
-	g.Edges[u.ID()][v.ID()] = w
-	g.Edges[v.ID()][u.ID()] = w
+	g.link(u.ID(), v.ID(), w)
+	g.link(v.ID(), u.ID(), w)
 	return g
 }
 
+// link is a helper function which sets the edge weight from u to v, and keeps the
+// adjacency list of u sorted by vertex number
+func (g *Graph) link(u, v VertexID, w Weight) {
+	g.Edges[u][v] = w
+	from, to := g.index[u], g.index[v]
+	edges := g.adjacency[from]
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].to >= to })
+	if i < len(edges) && edges[i].to == to {
+		edges[i].weight = w
+		return
+	}
+	edges = append(edges, edge{})
+	copy(edges[i+1:], edges[i:])
+	edges[i] = edge{to: to, weight: w}
+	g.adjacency[from] = edges
+}
+
 // Path records the stop and total weight from source to destination in a Graph
 type Path struct {
 	Stops  []Vertex
@@ -70,26 +103,63 @@ var ErrorSourceDestinationSame = errors.New("source and destination are the same
 // ErrorPathNotFound is returned by path-finding algorithms when no path exists.
 var ErrorPathNotFound = errors.New("path not found")
 
-// UnweightedSearch use Graph.BFS when all is false; otherwise use
-// Graph.DijkstraAll
-func (g *Graph) UnweightedSearch(src, dest VertexID, all bool) ([]Path, error) {
-	if all {
-		return g.DijkstraAll(src, dest)
+// UnweightedSearch use Graph.BFS when limit is 1; otherwise use
+// Graph.TimeDependentKShortestPaths with unit weight on every edge
+func (g *Graph) UnweightedSearch(src, dest VertexID, limit int, accept PathFilter) ([]Path, error) {
+	if limit > 1 {
+		return g.TimeDependentKShortestPaths(src, dest, limit, unitWeight, accept)
 	}
 	p, err := g.BFS(src, dest)
 	return []Path{p}, err
 }
 
-// WeightedSearch use Graph.Dijkstra when all is false; otherwise use
-// Graph.DijkstraAll
-func (g *Graph) WeightedSearch(src, dest VertexID, all bool) ([]Path, error) {
-	if all {
-		return g.DijkstraAll(src, dest)
+// WeightedSearch use Graph.TimeDependentDijkstra when limit is 1; otherwise use
+// Graph.TimeDependentKShortestPaths
+func (g *Graph) WeightedSearch(src, dest VertexID, limit int, weight EdgeWeightFunc, accept PathFilter) ([]Path, error) {
+	if limit > 1 {
+		return g.TimeDependentKShortestPaths(src, dest, limit, weight, accept)
 	}
-	p, err := g.Dijkstra(src, dest)
+	p, err := g.TimeDependentDijkstra(src, dest, weight)
 	return []Path{p}, err
 }
 
+// PathFilter tells if a Path found is acceptable as a search result.
+type PathFilter func(Path) bool
+
+// EdgeWeightFunc gives the weight of the edge from u to v, knowing the static weight w
+// stored in the Graph and the accumulated weight at u when the edge is taken.
+// It returns false when the edge cannot be taken at that moment.
+type EdgeWeightFunc func(u, v VertexID, w Weight, at Weight) (Weight, bool)
+
+// staticWeight is an EdgeWeightFunc always giving the weight stored in the Graph
+func staticWeight(_, _ VertexID, w Weight, _ Weight) (Weight, bool) {
+	return w, true
+}
+
+// unitWeight is an EdgeWeightFunc giving 1 to every edge
+func unitWeight(_, _ VertexID, _ Weight, _ Weight) (Weight, bool) {
+	return 1, true
+}
+
+// Evaluate sums up the weight along the stops with the weight function, and returns
+// false if any edge on the way is missing or cannot be taken.
+func (g *Graph) Evaluate(stops []Vertex, weight EdgeWeightFunc) (Weight, bool) {
+	var total Weight
+	for i := 1; i < len(stops); i++ {
+		u, v := stops[i-1].ID(), stops[i].ID()
+		w, ok := g.Edges[u][v]
+		if !ok {
+			return 0, false
+		}
+		w, ok = weight(u, v, w, total)
+		if !ok {
+			return 0, false
+		}
+		total += w
+	}
+	return total, true
+}
+
 // BFS finds the shortest path from source to destination and ignores edge weights.
 // It returns error when
 // 1) source or destination does not exist in the Graph;
@@ -99,21 +169,23 @@ func (g *Graph) BFS(src, dest VertexID) (Path, error) {
 	if err := validate(g, src, dest); err != nil {
 		return Path{}, err
 	}
-	parent := make(map[VertexID]VertexID)
-	visited := map[VertexID]bool{src: true}
-	queue := []VertexID{src}
+	s, d := g.index[src], g.index[dest]
+	parent := newParents(len(g.ids))
+	visited := make([]bool, len(g.ids))
+	visited[s] = true
+	queue := []int{s}
 	for len(queue) > 0 {
 		current := queue[0]
 		queue = queue[1:]
-		if current == dest {
+		if current == d {
 			path := g.backtrack(current, parent)
 			return Path{Stops: path, Weight: Weight(len(path) - 1)}, nil
 		}
-		for neighbor := range g.Edges[current] {
-			if !visited[neighbor] {
-				parent[neighbor] = current
-				visited[neighbor] = true
-				queue = append(queue, neighbor)
+		for _, e := range g.adjacency[current] {
+			if !visited[e.to] {
+				parent[e.to] = current
+				visited[e.to] = true
+				queue = append(queue, e.to)
 			}
 		}
 	}
@@ -126,111 +198,210 @@ func (g *Graph) BFS(src, dest VertexID) (Path, error) {
 // 2) source and destination are the same;
 // 3) no path is found.
 func (g *Graph) Dijkstra(src, dest VertexID) (Path, error) {
+	return g.TimeDependentDijkstra(src, dest, staticWeight)
+}
+
+// TimeDependentDijkstra finds the path with minimum weight from source to destination in
+// a Graph, where the weight of each edge is evaluated by the weight function at the
+// accumulated weight of its tail vertex. It assumes taking an edge later never arrives
+// earlier. It returns error when
+// 1) source or destination does not exist in the Graph;
+// 2) source and destination are the same;
+// 3) no path is found.
+func (g *Graph) TimeDependentDijkstra(src, dest VertexID, weight EdgeWeightFunc) (Path, error) {
 	if err := validate(g, src, dest); err != nil {
 		return Path{}, err
 	}
-	parent := make(map[VertexID]VertexID)
-	visited := map[VertexID]bool{src: true}
-	dist := map[VertexID]Weight{src: 0}
+	return g.dijkstra(src, dest, 0, weight)
+}
 
-	for len(dist) > 0 {
-		// pop the nearest vertex
-		current := minDist(dist)
-		currentWeight := dist[current]
-		delete(dist, current)
+// dijkstra is a helper function which searches the path with minimum weight starting
+// from source at the given accumulated weight, the returned Path weight includes it.
+func (g *Graph) dijkstra(src, dest VertexID, start Weight, weight EdgeWeightFunc) (Path, error) {
+	d := g.index[dest]
+	found, arrival := false, Weight(0)
+	parent := g.settle(src, start, weight, nil, func(current int, currentWeight Weight) bool {
+		found, arrival = current == d, currentWeight
+		return !found
+	})
+	if !found {
+		return Path{}, ErrorPathNotFound
+	}
+	return Path{Stops: g.backtrack(d, parent), Weight: arrival}, nil
+}
+
+// Reachable finds every vertex reachable from source within the budget of weight in a
+// Graph, including source itself, and returns the path with minimum weight to each of
+// them ordered by weight. A negative budget means no limit.
+// It returns error when source does not exist in the Graph.
+func (g *Graph) Reachable(src VertexID, budget Weight) ([]Path, error) {
+	return g.TimeDependentReachable(src, budget, staticWeight)
+}
+
+// TimeDependentReachable is Reachable where the weight of each edge is evaluated by the
+// weight function like TimeDependentDijkstra.
+func (g *Graph) TimeDependentReachable(src VertexID, budget Weight, weight EdgeWeightFunc) ([]Path, error) {
+	if _, ok := g.Vertices[src]; !ok {
+		return nil, ErrorSourceNotFound
+	}
+	settled := []queueItem{}
+	parent := g.settle(src, 0, weight, nil, func(current int, currentWeight Weight) bool {
+		if budget >= 0 && currentWeight > budget {
+			return false
+		}
+		settled = append(settled, queueItem{vertex: current, weight: currentWeight})
+		return true
+	})
+	result := []Path{}
+	for _, item := range settled {
+		result = append(result, Path{Stops: g.backtrack(item.vertex, parent), Weight: item.weight})
+	}
+	return result, nil
+}
+
+// settle is a helper function which runs Dijkstra's algorithm from source at the given
+// accumulated weight, and calls visit with each vertex in the order they are settled
+// until it returns false. It returns the parent list of the vertices settled.
+// When a heuristic is given, vertices are settled in the order of their weight plus the
+// heuristic instead, which is A* search.
+func (g *Graph) settle(src VertexID, start Weight, weight EdgeWeightFunc, heuristic func(v int) Weight, visit func(current int, currentWeight Weight) bool) []int {
+	if heuristic == nil {
+		heuristic = func(int) Weight { return 0 }
+	}
+	s := g.index[src]
+	parent := newParents(len(g.ids))
+	visited := make([]bool, len(g.ids))
+	reached := make([]bool, len(g.ids))
+	dist := make([]Weight, len(g.ids))
+	reached[s], dist[s] = true, start
+	queue := &priorityQueue{queueItem{vertex: s, weight: start + heuristic(s)}}
+
+	for queue.Len() > 0 {
+		// pop the nearest vertex, skipping the stale items of visited ones
+		current := heap.Pop(queue).(queueItem).vertex
+		if visited[current] {
+			continue
+		}
 		visited[current] = true
+		currentWeight := dist[current]
 
-		if current == dest {
-			p := g.backtrack(current, parent)
-			return Path{
-				Stops:  p,
-				Weight: currentWeight,
-			}, nil
+		if !visit(current, currentWeight) {
+			break
 		}
 
-		for neighbor, edgeWeight := range g.Edges[current] {
-			if !visited[neighbor] {
-				alt := currentWeight + edgeWeight
-				neighborWeight, ok := dist[neighbor]
-				if !ok || alt < neighborWeight {
-					dist[neighbor] = alt
-					parent[neighbor] = current
+		for _, e := range g.adjacency[current] {
+			if !visited[e.to] {
+				w, ok := weight(g.ids[current], g.ids[e.to], e.weight, currentWeight)
+				if !ok {
+					continue
+				}
+				alt := currentWeight + w
+				if !reached[e.to] || alt < dist[e.to] {
+					reached[e.to], dist[e.to] = true, alt
+					parent[e.to] = current
+					heap.Push(queue, queueItem{vertex: e.to, weight: alt + heuristic(e.to)})
 				}
 			}
 		}
 	}
-	return Path{}, ErrorPathNotFound
+	return parent
 }
 
-// DijkstraAll finds all the paths from source to destination in a Graph and sorts them by
-// total weight in descending order. It returns error when
+// KShortestPaths finds up to k loopless paths with minimum weight from source to
+// destination in a Graph using Yen's algorithm, and sorts them by total weight in
+// ascending order. It returns error when
 // 1) source or destination does not exist in the Graph;
 // 2) source and destination are the same;
 // 3) no path is found.
-func (g *Graph) DijkstraAll(src, dest VertexID) ([]Path, error) {
+func (g *Graph) KShortestPaths(src, dest VertexID, k int) ([]Path, error) {
+	return g.TimeDependentKShortestPaths(src, dest, k, staticWeight, nil)
+}
+
+// maxRejectedPaths bounds the paths rejected by the PathFilter per path requested,
+// as Yen's algorithm would otherwise enumerate every loopless path when few are acceptable
+const maxRejectedPaths = 10
+
+// TimeDependentKShortestPaths is KShortestPaths where the weight of each edge is
+// evaluated by the weight function like TimeDependentDijkstra. Paths rejected by the
+// accept filter are not returned but still explored for deviations; nil accepts all.
+func (g *Graph) TimeDependentKShortestPaths(src, dest VertexID, k int, weight EdgeWeightFunc, accept PathFilter) ([]Path, error) {
 	if err := validate(g, src, dest); err != nil {
 		return nil, err
 	}
+	if accept == nil {
+		accept = func(Path) bool { return true }
+	}
 
-	paths := []Path{}
-
-	parent := make(map[VertexID]VertexID)
-	visited := map[VertexID]bool{src: true}
-	dist := map[VertexID]Weight{src: 0}
+	first, err := g.dijkstra(src, dest, 0, weight)
+	if err != nil {
+		return nil, err
+	}
+	// shortest paths in the order found, and those accepted as result
+	shortest := []Path{first}
+	result := []Path{}
+	if accept(first) {
+		result = append(result, first)
+	}
+	found := map[string]bool{pathKey(first.Stops): true}
+	candidates := []Path{}
 
-	for len(dist) > 0 {
-		// pop the nearest vertex
-		current := minDist(dist)
-		currentWeight := dist[current]
-		delete(dist, current)
-		visited[current] = true
+	for len(result) < k && len(shortest)-len(result) < k*maxRejectedPaths {
+		prev := shortest[len(shortest)-1].Stops
+		// deviate from each vertex of the previous path, except the destination
+		for i := 0; i < len(prev)-1; i++ {
+			spur := prev[i].ID()
+			root := prev[:i+1]
 
-		if current == dest {
-			// only stop when all neighbors of dest have been visited
-			hasUnvisited := false
-			for neighbor := range g.Edges[current] {
-				if !visited[neighbor] {
-					hasUnvisited = true
-					break
+			// remove the edges leaving the root used by the paths found so far,
+			// and the vertices on the root so the path stays loopless
+			removedEdges := make(map[VertexID]bool)
+			for _, p := range shortest {
+				if len(p.Stops) > i+1 && pathKey(p.Stops[:i+1]) == pathKey(root) {
+					removedEdges[p.Stops[i+1].ID()] = true
 				}
 			}
-			if !hasUnvisited {
-				break
+			removedVertices := make(map[VertexID]bool)
+			for _, v := range root[:i] {
+				removedVertices[v.ID()] = true
 			}
-		}
-
-		for neighbor, edgeWeight := range g.Edges[current] {
-			// do not explore neighbors of destination
-			if current == dest {
-				break
+			spurWeight := func(u, v VertexID, w, at Weight) (Weight, bool) {
+				if removedVertices[v] || u == spur && removedEdges[v] {
+					return 0, false
+				}
+				return weight(u, v, w, at)
 			}
 
-			// record down the all the paths
-			if neighbor == dest {
-				p := append(g.backtrack(current, parent), *g.Vertices[dest])
-				paths = append(paths, Path{
-					Stops:  p,
-					Weight: currentWeight + edgeWeight,
-				})
+			rootWeight, ok := g.Evaluate(root, weight)
+			if !ok {
+				continue
 			}
-
-			if !visited[neighbor] {
-				alt := currentWeight + edgeWeight
-				neighborWeight, ok := dist[neighbor]
-				if !ok || alt < neighborWeight {
-					dist[neighbor] = alt
-					parent[neighbor] = current
-				}
+			spurPath, err := g.dijkstra(spur, dest, rootWeight, spurWeight)
+			if err != nil {
+				continue
 			}
+			stops := append(append([]Vertex{}, root[:i]...), spurPath.Stops...)
+			if key := pathKey(stops); !found[key] {
+				found[key] = true
+				candidates = append(candidates, Path{Stops: stops, Weight: spurPath.Weight})
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		// take the lightest candidate as the next shortest path
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Weight < candidates[j].Weight })
+		next := candidates[0]
+		candidates = candidates[1:]
+		shortest = append(shortest, next)
+		if accept(next) {
+			result = append(result, next)
 		}
 	}
-	// returns error if no path is found
-	if len(paths) == 0 {
+
+	if len(result) == 0 {
 		return nil, ErrorPathNotFound
 	}
-	// sort the paths by weight in descending order
-	sort.Slice(paths, func(i, j int) bool { return paths[i].Weight < paths[j].Weight })
-	return paths, nil
+	return result, nil
 }
 
 // validate the source and destination for path finding algorithms
@@ -247,29 +418,35 @@ func validate(g *Graph, src, dest VertexID) error {
 	return nil
 }
 
-// backtrack is a helper function which constructs the path with parent map
-func (g *Graph) backtrack(current VertexID, parent map[VertexID]VertexID) []Vertex {
-	path := []Vertex{*g.Vertices[current]}
-	for {
-		if p, ok := parent[current]; ok {
-			path = append([]Vertex{*g.Vertices[p]}, path...)
-			current = p
-		} else {
-			return path
-		}
+// newParents is a helper function which creates the parent list of vertices by number
+// for path-finding algorithms, where -1 stands for no parent
+func newParents(n int) []int {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = -1
 	}
+	return parent
 }
 
-// minDist is a helper function which finds the nearest unvisited vertex,
-// and it assumes non empty dist map
-func minDist(dist map[VertexID]Weight) VertexID {
-	var min VertexID
-	minWeight := math.MaxInt32
-	for v, w := range dist {
-		if int(w) < minWeight {
-			min = v
-			minWeight = int(w)
-		}
+// backtrack is a helper function which constructs the path with parent list
+func (g *Graph) backtrack(current int, parent []int) []Vertex {
+	path := []Vertex{*g.Vertices[g.ids[current]]}
+	for parent[current] >= 0 {
+		current = parent[current]
+		path = append(path, *g.Vertices[g.ids[current]])
+	}
+	// reverse to start from source
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// pathKey is a helper function which identifies a path by its vertices
+func pathKey(stops []Vertex) string {
+	ids := make([]VertexID, len(stops))
+	for i, v := range stops {
+		ids[i] = v.ID()
 	}
-	return min
+	return fmt.Sprintf("%#v", ids)
 }
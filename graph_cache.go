@@ -0,0 +1,194 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// graphKey identifies a Graph by the network snapshot, ie. the latest opening date of
+// Stations included, and the travel period whose TravelCost weights its edges.
+type graphKey struct {
+	snapshot time.Time
+	period   string
+}
+
+// periodByStops is a pseudo travel period for the Graph weighted by TravelCostByStop
+const periodByStops = "stops"
+
+// landmarkCount is the number of landmarks picked for the A* Heuristic of cached Graphs
+const landmarkCount = 4
+
+// cachedGraph holds a Graph with the Stations, Segments and TravelCost it is built from,
+// the rides between its adjacent Stations on each line, all Stations, the TravelCosts by period, the Calendar, the Schedule, the first and last
+// train times, the station coordinates and the aliases of station names by normalised
+// alias of the same network to price journeys on it, and the Heuristic for A* search on it
+type cachedGraph struct {
+	stations    []Station
+	segments    []Segment
+	hops        map[StationID][][]StationID
+	cost        TravelCost
+	graph       *Graph
+	allStations []Station
+	travelCosts map[string]TravelCost
+	coordinates map[StationID]Coordinates
+	aliases     map[string]string
+	calendar    Calendar
+	schedule    Schedule
+	services    serviceTimetable
+	heuristic   func() Heuristic
+}
+
+// setNetwork replaces the Stations, Segments, TravelCosts, station coordinates, aliases,
+// Calendar, Schedule and service hours of the Navigator by those of the Network, and
+// invalidates the cached Graphs built from the previous ones
+func (n *Navigator) setNetwork(network Network) {
+	travelCosts := network.travelCosts()
+
+	// distinct opening dates in ascending order
+	openingDates := []time.Time{}
+	for _, s := range network.stations {
+		openingDates = append(openingDates, s.openingDate)
+	}
+	sort.Slice(openingDates, func(i, j int) bool { return openingDates[i].Before(openingDates[j]) })
+	distinct := []time.Time{}
+	for i, d := range openingDates {
+		if i == 0 || !d.Equal(openingDates[i-1]) {
+			distinct = append(distinct, d)
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.allStations = network.stations
+	n.segments = network.segments
+	n.travelCosts = travelCosts
+	n.coordinates = network.coordinates
+	n.aliases = normalizeAliases(network.aliases)
+	n.calendar = newCalendar(network.holidays)
+	n.schedule = network.schedule
+	n.services = newServiceTimetable(network.serviceHours)
+	n.openingDates = distinct
+	n.graphs = make(map[graphKey]cachedGraph)
+}
+
+// graphByStops returns the Graph of all Stations weighted by TravelCostByStop
+func (n *Navigator) graphByStops() cachedGraph {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.cachedGraph(graphKey{period: periodByStops}, func() cachedGraph {
+		return cachedGraph{
+			stations:    n.allStations,
+			segments:    n.segments,
+			hops:        lineHops(n.allStations, n.segments),
+			cost:        TravelCostByStop{},
+			graph:       buildGraph(n.allStations, n.segments, TravelCostByStop{}),
+			allStations: n.allStations,
+			travelCosts: n.travelCosts,
+			coordinates: n.coordinates,
+			aliases:     n.aliases,
+			calendar:    n.calendar,
+			schedule:    n.schedule,
+			services:    n.services,
+		}.withHeuristic()
+	})
+}
+
+// graphAt returns the Graph of Stations operating at the given time, weighted by the
+// TravelCost of its travel period by the Schedule considering public holidays
+func (n *Navigator) graphAt(t time.Time) cachedGraph {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// the latest opening date not after the time of travel
+	var snapshot time.Time
+	if i := sort.Search(len(n.openingDates), func(i int) bool { return n.openingDates[i].After(t) }); i > 0 {
+		snapshot = n.openingDates[i-1]
+	}
+	period := n.schedule.travelPeriod(t, n.calendar)
+
+	return n.cachedGraph(graphKey{snapshot: snapshot, period: period}, func() cachedGraph {
+		openingStations := []Station{}
+		for _, station := range n.allStations {
+			// remove if travel before the station exists
+			if snapshot.Before(station.openingDate) {
+				continue
+			}
+			// remove if the line does not operate in the period, eg. DT, CG and CE at night
+			if n.schedule.isClosed(period, station.id.line) {
+				continue
+			}
+			openingStations = append(openingStations, station)
+		}
+		return cachedGraph{
+			stations:    openingStations,
+			segments:    n.segments,
+			hops:        lineHops(openingStations, n.segments),
+			cost:        n.travelCosts[period],
+			graph:       buildGraph(openingStations, n.segments, n.travelCosts[period]),
+			allStations: n.allStations,
+			travelCosts: n.travelCosts,
+			coordinates: n.coordinates,
+			aliases:     n.aliases,
+			calendar:    n.calendar,
+			schedule:    n.schedule,
+			services:    n.services,
+		}.withHeuristic()
+	})
+}
+
+// cachedGraph is a helper function to get the Graph by key or build it on cache miss,
+// and it assumes the caller holding the lock
+func (n *Navigator) cachedGraph(key graphKey, build func() cachedGraph) cachedGraph {
+	if c, ok := n.graphs[key]; ok {
+		return c
+	}
+	c := build()
+	n.graphs[key] = c
+	return c
+}
+
+// withHeuristic is a helper function which sets the landmarks Heuristic of the cached
+// Graph, where each edge is weighted by its lowest cost in any travel period. The
+// landmarks are only computed by the first A* search which asks for the Heuristic.
+func (c cachedGraph) withHeuristic() cachedGraph {
+	var once sync.Once
+	var heuristic Heuristic
+	c.heuristic = func() Heuristic {
+		once.Do(func() {
+			heuristic = c.graph.Landmarks(landmarkCount, c.lowerBoundWeight)
+		})
+		return heuristic
+	}
+	return c
+}
+
+// lowerBoundWeight is an EdgeWeightFunc giving the lowest cost of the edge by the
+// TravelCost of the cached Graph and the TravelCosts of all travel periods
+func (c cachedGraph) lowerBoundWeight(u, v VertexID, _ Weight, _ Weight) (Weight, bool) {
+	from, to := u.(StationID), v.(StationID)
+	price := func(cost TravelCost) Weight {
+		if from.line == to.line {
+			return c.onLine(cost, from, to)
+		}
+		return cost.Interchange(from, to)
+	}
+	lowest := price(c.cost)
+	for _, cost := range c.travelCosts {
+		if w := price(cost); w < lowest {
+			lowest = w
+		}
+	}
+	return lowest, true
+}
+
+// onLine is a helper function to price the ride between adjacent Stations on the line of
+// the cached Graph by the TravelCost, adding up the Segments passed through on the way
+func (c cachedGraph) onLine(cost TravelCost, from, to StationID) Weight {
+	for _, hop := range c.hops[from] {
+		if hop[len(hop)-1] == to {
+			return rideCost(cost, hop)
+		}
+	}
+	return cost.OnLine(from, to)
+}
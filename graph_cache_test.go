@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGraphCache(t *testing.T) {
+	n := defaultNavigator()
+	timeLayout := "2006-01-02T15:04"
+	peak, _ := time.ParseInLocation(timeLayout, "2020-11-09T06:01", networkLocation)
+	peakLater, _ := time.ParseInLocation(timeLayout, "2020-11-10T18:30", networkLocation)
+	nonPeak, _ := time.ParseInLocation(timeLayout, "2020-11-09T10:00", networkLocation)
+	beforeTE, _ := time.ParseInLocation(timeLayout, "2019-11-11T06:01", networkLocation)
+
+	if n.graphAt(peak).graph != n.graphAt(peakLater).graph {
+		t.Errorf("expect same Graph for same snapshot and period")
+	}
+	if n.graphAt(peak).graph == n.graphAt(nonPeak).graph {
+		t.Errorf("expect different Graph for different period")
+	}
+	if n.graphAt(peak).graph == n.graphAt(beforeTE).graph {
+		t.Errorf("expect different Graph for different snapshot")
+	}
+	if n.graphByStops().graph != n.graphByStops().graph {
+		t.Errorf("expect same Graph by stops")
+	}
+
+	cached := n.graphAt(peak).graph
+	n.setNetwork(defaultNetwork())
+	if n.graphAt(peak).graph == cached {
+		t.Errorf("expect Graph rebuilt after network changed")
+	}
+}
+
+func TestGraphCacheConcurrent(t *testing.T) {
+	n := defaultNavigator()
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T06:01", networkLocation)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := n.NavigateByTime("Jurong East", "HarbourFront", travelTime, NavigateOptions{Limit: 2}); err != nil {
+				t.Error(err)
+			}
+			if _, err := n.NavigateByStops("Jurong East", "HarbourFront", NavigateOptions{Limit: 2}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
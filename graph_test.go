@@ -130,9 +130,9 @@ func TestDijkstraError(t *testing.T) {
 	}
 }
 
-func TestDijkstraAllError(t *testing.T) {
+func TestKShortestPathsError(t *testing.T) {
 	for _, testCase := range expectedError {
-		_, err := testCase.g.DijkstraAll(testCase.src, testCase.dest)
+		_, err := testCase.g.KShortestPaths(testCase.src, testCase.dest, 3)
 		if err == nil {
 			t.Errorf("expect error on %s", testCase.name)
 		}
@@ -239,9 +239,10 @@ func TestDijkstra(t *testing.T) {
 	}
 }
 
-func TestDijkstraAll(t *testing.T) {
+func TestKShortestPaths(t *testing.T) {
 	for _, testCase := range weightedTestCases {
-		actual, err := testCase.g.DijkstraAll(testCase.src, testCase.dest)
+		// ask for one more than all the loopless paths in the Graph
+		actual, err := testCase.g.KShortestPaths(testCase.src, testCase.dest, len(testCase.expected)+1)
 		if err != nil {
 			t.Errorf("expected: %v, actual error: %s", testCase.expected, err)
 		}
@@ -251,9 +252,159 @@ func TestDijkstraAll(t *testing.T) {
 	}
 }
 
+func TestKShortestPathsFilter(t *testing.T) {
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 2).
+		LinkBoth(IntVertex(2), IntVertex(3), 2).
+		LinkBoth(IntVertex(3), IntVertex(4), 1).
+		LinkBoth(IntVertex(4), IntVertex(5), 1).
+		LinkBoth(IntVertex(5), IntVertex(1), 1)
+	// reject paths passing through vertex 5
+	accept := func(p Path) bool {
+		for _, v := range p.Stops {
+			if v.ID() == 5 {
+				return false
+			}
+		}
+		return true
+	}
+	expected := []Path{
+		Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3)}, Weight: 4},
+	}
+	actual, err := g.TimeDependentKShortestPaths(1, 3, 2, staticWeight, accept)
+	if err != nil {
+		t.Errorf("expected: %v, actual error: %s", expected, err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestDijkstraDeterministic(t *testing.T) {
+	// two routes of equal weight from 1 to 4
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(1), IntVertex(3), 1).
+		LinkBoth(IntVertex(2), IntVertex(4), 1).
+		LinkBoth(IntVertex(3), IntVertex(4), 1)
+	expected := Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(4)}, Weight: 2}
+
+	for i := 0; i < 100; i++ {
+		actual, err := g.Dijkstra(1, 4)
+		if err != nil {
+			t.Fatalf("expected: %v, actual error: %s", expected, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected: %v, actual: %v", expected, actual)
+		}
+	}
+}
+
+func TestTimeDependentDijkstra(t *testing.T) {
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(2), IntVertex(3), 1).
+		LinkBoth(IntVertex(1), IntVertex(3), 5)
+
+	for _, testCase := range []struct {
+		name     string
+		weight   EdgeWeightFunc
+		expected Path
+	}{
+		{
+			name:     "static",
+			weight:   staticWeight,
+			expected: Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3)}, Weight: 2},
+		},
+		{
+			name: "edge closed when reached late",
+			weight: func(u, v VertexID, w, at Weight) (Weight, bool) {
+				return w, !(u == 2 && v == 3 && at >= 1)
+			},
+			expected: Path{Stops: []Vertex{IntVertex(1), IntVertex(3)}, Weight: 5},
+		},
+		{
+			name: "edge slows down when reached late",
+			weight: func(u, v VertexID, w, at Weight) (Weight, bool) {
+				if at >= 1 {
+					return w * 10, true
+				}
+				return w, true
+			},
+			expected: Path{Stops: []Vertex{IntVertex(1), IntVertex(3)}, Weight: 5},
+		},
+	} {
+		actual, err := g.TimeDependentDijkstra(1, 3, testCase.weight)
+		if err != nil {
+			t.Errorf("%s expected: %v, actual error: %s", testCase.name, testCase.expected, err)
+		}
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("%s expected: %v, actual: %v", testCase.name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(2), IntVertex(3), 2)
+	increasing := func(_, _ VertexID, w, at Weight) (Weight, bool) { return w + at, true }
+
+	if w, ok := g.Evaluate([]Vertex{IntVertex(1), IntVertex(2), IntVertex(3)}, increasing); !ok || w != 4 {
+		t.Errorf("expected: 4, actual: %d, %v", w, ok)
+	}
+	if _, ok := g.Evaluate([]Vertex{IntVertex(1), IntVertex(3)}, staticWeight); ok {
+		t.Errorf("expect missing edge not evaluated")
+	}
+}
+
+func TestGraphReachable(t *testing.T) {
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(2), IntVertex(3), 1).
+		LinkBoth(IntVertex(1), IntVertex(3), 5).
+		LinkBoth(IntVertex(3), IntVertex(4), 3)
+
+	for _, testCase := range []struct {
+		budget   Weight
+		expected []Path
+	}{
+		{
+			budget: 2,
+			expected: []Path{
+				Path{Stops: []Vertex{IntVertex(1)}, Weight: 0},
+				Path{Stops: []Vertex{IntVertex(1), IntVertex(2)}, Weight: 1},
+				Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3)}, Weight: 2},
+			},
+		},
+		{
+			budget: -1,
+			expected: []Path{
+				Path{Stops: []Vertex{IntVertex(1)}, Weight: 0},
+				Path{Stops: []Vertex{IntVertex(1), IntVertex(2)}, Weight: 1},
+				Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3)}, Weight: 2},
+				Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3), IntVertex(4)}, Weight: 5},
+			},
+		},
+	} {
+		actual, err := g.Reachable(1, testCase.budget)
+		if err != nil {
+			t.Errorf("budget %d unexpected error: %s", testCase.budget, err)
+		}
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("budget %d expected: %v, actual: %v", testCase.budget, testCase.expected, actual)
+		}
+	}
+
+	if _, err := g.Reachable(9, 1); err != ErrorSourceNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorSourceNotFound, err)
+	}
+}
+
 //// Benchmarks on path searching algorithms
 func BenchmarkGraphBFS(b *testing.B) {
-	var g = buildGraph(loadAllStations(), TravelCostByStop{})
+	var network = defaultNetwork()
+	var g = buildGraph(network.stations, network.segments, TravelCostByStop{})
 	var source = StationID{line: "CC", number: 19}
 	var destination = StationID{line: "DT", number: 15}
 
@@ -263,7 +414,8 @@ func BenchmarkGraphBFS(b *testing.B) {
 }
 
 func BenchmarkGraphDijkstra(b *testing.B) {
-	var g = buildGraph(loadAllStations(), TravelCostByStop{})
+	var network = defaultNetwork()
+	var g = buildGraph(network.stations, network.segments, TravelCostByStop{})
 	var source = StationID{line: "CC", number: 19}
 	var destination = StationID{line: "DT", number: 15}
 
@@ -272,12 +424,13 @@ func BenchmarkGraphDijkstra(b *testing.B) {
 	}
 }
 
-func BenchmarkGraphDijkstraAll(b *testing.B) {
-	var g = buildGraph(loadAllStations(), TravelCostByStop{})
+func BenchmarkGraphKShortestPaths(b *testing.B) {
+	var network = defaultNetwork()
+	var g = buildGraph(network.stations, network.segments, TravelCostByStop{})
 	var source = StationID{line: "CC", number: 19}
 	var destination = StationID{line: "DT", number: 15}
 
 	for i := 0; i < b.N; i++ {
-		g.DijkstraAll(source, destination)
+		g.KShortestPaths(source, destination, 3)
 	}
 }
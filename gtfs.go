@@ -0,0 +1,280 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NewNavigatorFromGTFS reads the Network from a GTFS feed at the given path, either a zip
+// file or a directory, and returns a Navigator instance or any error encountered
+//
+// Deprecated: use NewNavigator(WithGTFS(path)) instead.
+func NewNavigatorFromGTFS(path string) (*Navigator, error) {
+	return NewNavigator(WithGTFS(path))
+}
+
+// ReadGTFS reads the Network from a GTFS feed at the given path, either a zip file or a
+// directory. See readGTFS for how the feed is mapped.
+func ReadGTFS(feedPath string) (Network, error) {
+	info, err := os.Stat(feedPath)
+	if err != nil {
+		return Network{}, err
+	}
+	if info.IsDir() {
+		return readGTFS(func(name string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(feedPath, name))
+		})
+	}
+
+	zipReader, err := zip.OpenReader(feedPath)
+	if err != nil {
+		return Network{}, err
+	}
+	defer zipReader.Close()
+	return readGTFS(func(name string) (io.ReadCloser, error) {
+		for _, f := range zipReader.File {
+			// the files may be nested in a folder of the zip file
+			if path.Base(f.Name) == name {
+				return f.Open()
+			}
+		}
+		return nil, os.ErrNotExist
+	})
+}
+
+// readGTFS is a helper function which reads the Network from the files of a GTFS feed
+// opened by name:
+//
+// 1) stops.txt gives the Stations, where stop_code (or stop_id when it is empty) is the
+// StationID, unique among the stops, and stop_lat and stop_lon are the coordinates.
+// Stations are open since always, as GTFS does not tell the opening dates;
+//
+// 2) consecutive stops of each trip in stop_times.txt give the line Segments, and the
+// average minutes between departure and arrival over all trips give the running times
+// for all travel periods. trips.txt and routes.txt tell the route of each trip;
+//
+// 3) the optional transfers.txt gives the walking times of interchanges by
+// min_transfer_time, where transfers other than between Stations of the same name on
+// different lines are ignored.
+func readGTFS(open func(name string) (io.ReadCloser, error)) (Network, error) {
+	network := Network{coordinates: make(map[StationID]Coordinates)}
+
+	// stops
+	stops, err := readGTFSTable(open, "stops.txt")
+	if err != nil {
+		return Network{}, err
+	}
+	stations := make(map[string]Station)
+	stopIDs := make(map[StationID]string)
+	for _, stop := range stops {
+		if stop["location_type"] != "" && stop["location_type"] != "0" {
+			continue
+		}
+		code := stop["stop_code"]
+		if code == "" {
+			code = stop["stop_id"]
+		}
+		id, err := NewStationID(code)
+		if err != nil {
+			return Network{}, fmt.Errorf("stop %s: %v", stop["stop_id"], err)
+		}
+		// Stations are identified by the code, so two stops of one code would merge
+		if other, ok := stopIDs[id]; ok {
+			return Network{}, fmt.Errorf("stop %s: duplicate station code %s of stop %s", stop["stop_id"], id, other)
+		}
+		stopIDs[id] = stop["stop_id"]
+		s := Station{id: id, name: stop["stop_name"]}
+		stations[stop["stop_id"]] = s
+		network.stations = append(network.stations, s)
+
+		lat, errLat := strconv.ParseFloat(stop["stop_lat"], 64)
+		lon, errLon := strconv.ParseFloat(stop["stop_lon"], 64)
+		if errLat == nil && errLon == nil {
+			network.coordinates[id] = Coordinates{Latitude: lat, Longitude: lon}
+		}
+	}
+
+	// routes of trips
+	routes, err := readGTFSTable(open, "routes.txt")
+	if err != nil {
+		return Network{}, err
+	}
+	routeNames := make(map[string]string)
+	for _, route := range routes {
+		routeNames[route["route_id"]] = route["route_short_name"]
+	}
+	trips, err := readGTFSTable(open, "trips.txt")
+	if err != nil {
+		return Network{}, err
+	}
+	tripRoutes := make(map[string]string)
+	for _, trip := range trips {
+		if _, ok := routeNames[trip["route_id"]]; !ok {
+			return Network{}, fmt.Errorf("trip %s of unknown route %s", trip["trip_id"], trip["route_id"])
+		}
+		tripRoutes[trip["trip_id"]] = trip["route_id"]
+	}
+
+	// stop times grouped by trip in the order of stop sequence
+	stopTimes, err := readGTFSTable(open, "stop_times.txt")
+	if err != nil {
+		return Network{}, err
+	}
+	type tripStop struct {
+		sequence  int
+		station   Station
+		arrival   string
+		departure string
+	}
+	tripStops := make(map[string][]tripStop)
+	tripOrder := []string{}
+	for _, st := range stopTimes {
+		tripID := st["trip_id"]
+		if _, ok := tripRoutes[tripID]; !ok {
+			return Network{}, fmt.Errorf("stop time of unknown trip %s", tripID)
+		}
+		s, ok := stations[st["stop_id"]]
+		if !ok {
+			return Network{}, fmt.Errorf("stop time of trip %s at unknown stop %s", tripID, st["stop_id"])
+		}
+		sequence, err := strconv.Atoi(st["stop_sequence"])
+		if err != nil {
+			return Network{}, err
+		}
+		if _, ok := tripStops[tripID]; !ok {
+			tripOrder = append(tripOrder, tripID)
+		}
+		tripStops[tripID] = append(tripStops[tripID], tripStop{sequence: sequence, station: s, arrival: st["arrival_time"], departure: st["departure_time"]})
+	}
+
+	// segments and their running times averaged over trips
+	seconds := make(map[Segment][]int)
+	for _, tripID := range tripOrder {
+		ts := tripStops[tripID]
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].sequence < ts[j].sequence })
+		for i := 1; i < len(ts); i++ {
+			from, to := ts[i-1].station.id, ts[i].station.id
+			if from == to {
+				continue
+			}
+			if from.line != to.line {
+				return Network{}, fmt.Errorf("trip %s of route %s connects different lines %s-%s", tripID, routeNames[tripRoutes[tripID]], from, to)
+			}
+			seg := Segment{from: from, to: to}
+			if _, ok := seconds[Segment{from: to, to: from}]; ok {
+				seg = Segment{from: to, to: from}
+			}
+			if _, ok := seconds[seg]; !ok {
+				network.segments = append(network.segments, seg)
+				seconds[seg] = []int{}
+			}
+			departure, errDeparture := parseGTFSTime(ts[i-1].departure)
+			arrival, errArrival := parseGTFSTime(ts[i].arrival)
+			if errDeparture == nil && errArrival == nil && arrival >= departure {
+				seconds[seg] = append(seconds[seg], arrival-departure)
+			}
+		}
+	}
+	for _, seg := range network.segments {
+		if len(seconds[seg]) == 0 {
+			continue
+		}
+		total := 0
+		for _, s := range seconds[seg] {
+			total += s
+		}
+		minutes := Weight(math.Round(float64(total) / float64(len(seconds[seg])) / 60))
+		if minutes < 1 {
+			minutes = 1
+		}
+		network.segmentTimes = append(network.segmentTimes, SegmentTime{segment: seg, minutes: minutes})
+	}
+
+	// interchange times from the optional transfers
+	transfers, err := readGTFSTable(open, "transfers.txt")
+	if err != nil && !os.IsNotExist(err) {
+		return Network{}, err
+	}
+	for _, transfer := range transfers {
+		from, okFrom := stations[transfer["from_stop_id"]]
+		to, okTo := stations[transfer["to_stop_id"]]
+		if !okFrom || !okTo || from.id.line == to.id.line || from.name != to.name {
+			continue
+		}
+		transferSeconds, err := strconv.Atoi(transfer["min_transfer_time"])
+		if err != nil || transferSeconds <= 0 {
+			continue
+		}
+		network.interchangeTimes = append(network.interchangeTimes, InterchangeTime{
+			from:    from.id,
+			to:      to.id,
+			minutes: Weight(math.Ceil(float64(transferSeconds) / 60)),
+		})
+	}
+
+	return network, nil
+}
+
+// readGTFSTable is a helper function to read a csv file of a GTFS feed as rows keyed by
+// the column names in header
+func readGTFSTable(open func(name string) (io.ReadCloser, error), name string) ([]map[string]string, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	csvReader := csv.NewReader(f)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if len(header) > 0 {
+		// strip the byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	rows := []map[string]string{}
+	for _, record := range records {
+		row := make(map[string]string)
+		for i, column := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseGTFSTime is a helper function to parse the time in format HH:MM:SS as seconds of
+// the service day, where the hours may exceed 24 for trips past midnight
+func parseGTFSTime(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid GTFS time %q", s)
+	}
+	total := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid GTFS time %q", s)
+		}
+		total = total*60 + n
+	}
+	return total, nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// gtfsHeadwaySeconds are the synthetic headways of trains by travel period in the
+// exported GTFS feed for lines without headways in the Schedule, and
+// gtfsDefaultHeadwaySeconds is that of other periods
+var gtfsHeadwaySeconds = map[string]int{
+	periodPeak:    180,
+	periodNonPeak: 300,
+	periodNight:   600,
+}
+
+const gtfsDefaultHeadwaySeconds = 300
+
+// gtfsService is a service of the exported GTFS feed running on the given days of week,
+// with the travel period windows of a sample day
+type gtfsService struct {
+	id      string
+	days    [7]bool // Monday to Sunday
+	windows []gtfsWindow
+}
+
+// gtfsWindow is a time window of a day in the same travel period, in seconds of the day
+type gtfsWindow struct {
+	period string
+	start  int
+	end    int
+}
+
+// gtfsServices is a helper function to create the weekday and weekend services, whose
+// windows are derived from the travel periods of the Schedule on the first Monday and
+// Saturday since the given time, regardless of public holidays
+func gtfsServices(schedule Schedule, t time.Time) []gtfsService {
+	sampleDay := func(weekday time.Weekday) time.Time {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+	}
+	return []gtfsService{
+		newGTFSService("WD", [7]bool{true, true, true, true, true, false, false}, schedule, sampleDay(time.Monday)),
+		newGTFSService("WE", [7]bool{false, false, false, false, false, true, true}, schedule, sampleDay(time.Saturday)),
+	}
+}
+
+// newGTFSService is a helper function to create the gtfsService by merging the travel
+// periods of consecutive minutes of the sample day into windows
+func newGTFSService(id string, days [7]bool, schedule Schedule, day time.Time) gtfsService {
+	s := gtfsService{id: id, days: days}
+	for m := 0; m < minutesPerDay; m++ {
+		period := schedule.travelPeriod(day.Add(time.Duration(m)*time.Minute), nil)
+		if last := len(s.windows) - 1; last >= 0 && s.windows[last].period == period {
+			s.windows[last].end += 60
+			continue
+		}
+		s.windows = append(s.windows, gtfsWindow{period: period, start: m * 60, end: (m + 1) * 60})
+	}
+	return s
+}
+
+// gtfsTransferPeriod is a helper function to pick the travel period whose Graph gives the
+// walking times of interchanges and the line adjacency, which is nonpeak if scheduled
+func gtfsTransferPeriod(schedule Schedule) string {
+	names := schedule.periodNames()
+	if containsString(names, periodNonPeak) {
+		return periodNonPeak
+	}
+	return names[0]
+}
+
+// WriteGTFS writes the network of Stations operating at the given time as a GTFS feed in
+// zip format. Each line is split at its ends and branches into chains of Stations, by the
+// line adjacency of the Graph, and each chain is served by synthetic trips of each
+// direction, service and travel period of the Schedule with running times by the
+// TravelCost of the period and repeated by frequencies.txt, except for lines closed in the
+// period or outside the first and last train times of the line. Interchanges are written
+// to transfers.txt with their walking times in non-peak hours.
+func (n *Navigator) WriteGTFS(w io.Writer, t time.Time) error {
+	t = t.In(networkLocation)
+	n.mu.Lock()
+	allStations, segments, travelCosts, coordinates, schedule, timetable := n.allStations, n.segments, n.travelCosts, n.coordinates, n.schedule, n.services
+	n.mu.Unlock()
+
+	stations := []Station{}
+	for _, s := range allStations {
+		if !t.Before(s.openingDate) {
+			stations = append(stations, s)
+		}
+	}
+	graphs := make(map[string]*Graph)
+	for _, period := range schedule.periodNames() {
+		graphs[period] = buildGraph(stations, segments, travelCosts[period])
+	}
+
+	services := gtfsServices(schedule, t)
+
+	zipWriter := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		records [][]string
+	}{
+		{"agency.txt", gtfsAgency()},
+		{"stops.txt", gtfsStops(stations, coordinates)},
+		{"routes.txt", gtfsRoutes(stations)},
+		{"calendar.txt", gtfsCalendar(services, t)},
+	}
+	trips, stopTimes, frequencies := gtfsTrips(stations, graphs, services, schedule, timetable)
+	files = append(files, []struct {
+		name    string
+		records [][]string
+	}{
+		{"trips.txt", trips},
+		{"stop_times.txt", stopTimes},
+		{"frequencies.txt", frequencies},
+		{"transfers.txt", gtfsTransfers(stations, graphs[gtfsTransferPeriod(schedule)])},
+	}...)
+
+	for _, file := range files {
+		fw, err := zipWriter.Create(file.name)
+		if err != nil {
+			return err
+		}
+		csvWriter := csv.NewWriter(fw)
+		if err := csvWriter.WriteAll(file.records); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+// gtfsAgency is a helper function to make the records of agency.txt
+func gtfsAgency() [][]string {
+	return [][]string{
+		{"agency_id", "agency_name", "agency_url", "agency_timezone"},
+		{"MRT", "Singapore MRT", "https://www.lta.gov.sg", "Asia/Singapore"},
+	}
+}
+
+// gtfsStops is a helper function to make the records of stops.txt, where the stop_id and
+// stop_code are both the StationID
+func gtfsStops(stations []Station, coordinates map[StationID]Coordinates) [][]string {
+	records := [][]string{{"stop_id", "stop_code", "stop_name", "stop_lat", "stop_lon"}}
+	for _, s := range stations {
+		lat, lon := "", ""
+		if c, ok := coordinates[s.id]; ok {
+			lat = strconv.FormatFloat(c.Latitude, 'f', -1, 64)
+			lon = strconv.FormatFloat(c.Longitude, 'f', -1, 64)
+		}
+		records = append(records, []string{s.id.String(), s.id.String(), s.name, lat, lon})
+	}
+	return records
+}
+
+// gtfsRoutes is a helper function to make the records of routes.txt, one subway route
+// for each line
+func gtfsRoutes(stations []Station) [][]string {
+	records := [][]string{{"route_id", "agency_id", "route_short_name", "route_type"}}
+	for _, line := range gtfsLines(stations) {
+		records = append(records, []string{line, "MRT", line, "1"})
+	}
+	return records
+}
+
+// gtfsCalendar is a helper function to make the records of calendar.txt, where the
+// services run for a year since the given time
+func gtfsCalendar(services []gtfsService, t time.Time) [][]string {
+	records := [][]string{{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"}}
+	for _, s := range services {
+		record := []string{s.id}
+		for _, runs := range s.days {
+			if runs {
+				record = append(record, "1")
+			} else {
+				record = append(record, "0")
+			}
+		}
+		record = append(record, t.Format("20060102"), t.AddDate(1, 0, -1).Format("20060102"))
+		records = append(records, record)
+	}
+	return records
+}
+
+// gtfsTrips is a helper function to make the records of trips.txt, stop_times.txt and
+// frequencies.txt, where a trip of each chain, direction, service and travel period is
+// timed from the start of its first window and repeated in all its windows of the day
+// within the first and last train times of the line
+func gtfsTrips(stations []Station, graphs map[string]*Graph, services []gtfsService, schedule Schedule, timetable serviceTimetable) ([][]string, [][]string, [][]string) {
+	trips := [][]string{{"route_id", "service_id", "trip_id", "direction_id"}}
+	stopTimes := [][]string{{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"}}
+	frequencies := [][]string{{"trip_id", "start_time", "end_time", "headway_secs", "exact_times"}}
+
+	for _, line := range gtfsLines(stations) {
+		for i, chain := range lineChains(line, stations, graphs[gtfsTransferPeriod(schedule)]) {
+			reversed := make([]StationID, len(chain))
+			for j, id := range chain {
+				reversed[len(chain)-1-j] = id
+			}
+			for direction, stops := range [][]StationID{chain, reversed} {
+				for _, service := range services {
+					for _, period := range schedule.periodNames() {
+						windows := []gtfsWindow{}
+						for _, window := range service.windows {
+							if window.period == period {
+								windows = append(windows, window)
+							}
+						}
+						if hours, ok := timetable[serviceKey{line: line}]; ok {
+							windows = clipGTFSWindows(windows, hours)
+						}
+						if len(windows) == 0 || schedule.isClosed(period, line) {
+							continue
+						}
+
+						tripID := fmt.Sprintf("%s_%d_%d_%s_%s", line, i, direction, service.id, period)
+						trips = append(trips, []string{line, service.id, tripID, strconv.Itoa(direction)})
+						seconds := windows[0].start
+						for j, id := range stops {
+							if j > 0 {
+								seconds += int(graphs[period].Edges[stops[j-1]][id]) * 60
+							}
+							stopTimes = append(stopTimes, []string{tripID, formatGTFSTime(seconds), formatGTFSTime(seconds), id.String(), strconv.Itoa(j + 1)})
+						}
+						headway := int(schedule.headway(period, line)) * 60
+						if headway == 0 {
+							var ok bool
+							if headway, ok = gtfsHeadwaySeconds[period]; !ok {
+								headway = gtfsDefaultHeadwaySeconds
+							}
+						}
+						for _, window := range windows {
+							frequencies = append(frequencies, []string{tripID, formatGTFSTime(window.start), formatGTFSTime(window.end), strconv.Itoa(headway), "0"})
+						}
+					}
+				}
+			}
+		}
+	}
+	return trips, stopTimes, frequencies
+}
+
+// clipGTFSWindows is a helper function to cut the windows down to the minutes of the day
+// in service by the ServiceHours
+func clipGTFSWindows(windows []gtfsWindow, hours ServiceHours) []gtfsWindow {
+	clipped := []gtfsWindow{}
+	for _, window := range windows {
+		for _, interval := range hours.intervals() {
+			start, end := window.start, window.end
+			if interval[0]*60 > start {
+				start = interval[0] * 60
+			}
+			if interval[1]*60 < end {
+				end = interval[1] * 60
+			}
+			if start < end {
+				clipped = append(clipped, gtfsWindow{period: window.period, start: start, end: end})
+			}
+		}
+	}
+	return clipped
+}
+
+// gtfsTransfers is a helper function to make the records of transfers.txt, both ways for
+// each pair of interchange Stations with the walking time weighted in the Graph
+func gtfsTransfers(stations []Station, g *Graph) [][]string {
+	records := [][]string{{"from_stop_id", "to_stop_id", "transfer_type", "min_transfer_time"}}
+	for _, from := range stations {
+		for _, to := range stations {
+			if from.name != to.name || from.id == to.id {
+				continue
+			}
+			minutes := g.Edges[from.id][to.id]
+			records = append(records, []string{from.id.String(), to.id.String(), "2", strconv.Itoa(int(minutes) * 60)})
+		}
+	}
+	return records
+}
+
+// gtfsLines is a helper function to list the lines of Stations in order of appearance
+func gtfsLines(stations []Station) []string {
+	lines := []string{}
+	seen := make(map[string]bool)
+	for _, s := range stations {
+		if !seen[s.id.line] {
+			seen[s.id.line] = true
+			lines = append(lines, s.id.line)
+		}
+	}
+	return lines
+}
+
+// lineChains splits the Stations of a line into chains by the adjacency along the line in
+// the Graph, where a chain runs between the ends or branching Stations of the line, and a
+// loop without any of them runs from its smallest StationID back to itself. A Station
+// without adjacent ones on the line is left out, as a trip needs at least two stops.
+func lineChains(line string, stations []Station, g *Graph) [][]StationID {
+	ids := []StationID{}
+	adjacent := make(map[StationID][]StationID)
+	for _, s := range stations {
+		if s.id.line != line {
+			continue
+		}
+		ids = append(ids, s.id)
+		for v := range g.Edges[s.id] {
+			if next := v.(StationID); next.line == line {
+				adjacent[s.id] = append(adjacent[s.id], next)
+			}
+		}
+		sort.Slice(adjacent[s.id], func(i, j int) bool { return adjacent[s.id][i].number < adjacent[s.id][j].number })
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].number < ids[j].number })
+
+	chains := [][]StationID{}
+	visited := make(map[Segment]bool)
+	walk := func(from StationID, isStart func(StationID) bool) {
+		for _, next := range adjacent[from] {
+			if visited[Segment{from: from, to: next}] {
+				continue
+			}
+			chain := []StationID{from}
+			prev, current := from, next
+			for {
+				visited[Segment{from: prev, to: current}] = true
+				visited[Segment{from: current, to: prev}] = true
+				chain = append(chain, current)
+				if isStart(current) {
+					break
+				}
+				found := false
+				for _, after := range adjacent[current] {
+					if !visited[Segment{from: current, to: after}] {
+						prev, current, found = current, after, true
+						break
+					}
+				}
+				if !found {
+					break
+				}
+			}
+			chains = append(chains, chain)
+		}
+	}
+	isEnd := func(id StationID) bool { return len(adjacent[id]) != 2 }
+	for _, id := range ids {
+		if isEnd(id) {
+			walk(id, isEnd)
+		}
+	}
+	for _, id := range ids {
+		walk(id, func(current StationID) bool { return current == id })
+	}
+	return chains
+}
+
+// formatGTFSTime is a helper function to format seconds of the service day in HH:MM:SS
+func formatGTFSTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteGTFS(t *testing.T) {
+	n := defaultNavigator()
+	exportTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	var buf bytes.Buffer
+	if err := n.WriteGTFS(&buf, exportTime); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ioutil.TempFile("", "gtfs*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// the exported feed imports back into the network operating at the time
+	imported, err := NewNavigator(WithGTFS(f.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := len(n.graphAt(exportTime).stations), len(imported.allStations); expected != actual {
+		t.Errorf("stations expected: %d, actual: %d", expected, actual)
+	}
+	for _, pair := range [][2]string{
+		{"Jurong East", "Changi Airport"},
+		{"Punggol", "Woodlands North"},
+		{"Botanic Gardens", "Caldecott"},
+	} {
+		expected, err := n.NavigateByStops(pair[0], pair[1], NavigateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := imported.NavigateByStops(pair[0], pair[1], NavigateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected[0].Weight != actual[0].Weight {
+			t.Errorf("%s-%s expected: %d, actual: %d", pair[0], pair[1], expected[0].Weight, actual[0].Weight)
+		}
+	}
+}
+
+func TestLineChains(t *testing.T) {
+	stations := []Station{}
+	for _, id := range []StationID{{"AB", 1}, {"AB", 2}, {"AB", 3}, {"AB", 4}, {"AB", 5}, {"CD", 1}, {"CD", 2}, {"CD", 3}, {"EF", 1}} {
+		stations = append(stations, Station{id: id, name: id.String()})
+	}
+	segments := []Segment{
+		// AB2 branches to AB3 and AB5
+		{from: StationID{"AB", 1}, to: StationID{"AB", 2}},
+		{from: StationID{"AB", 2}, to: StationID{"AB", 3}},
+		{from: StationID{"AB", 3}, to: StationID{"AB", 4}},
+		{from: StationID{"AB", 2}, to: StationID{"AB", 5}},
+		// CD is a loop
+		{from: StationID{"CD", 1}, to: StationID{"CD", 2}},
+		{from: StationID{"CD", 2}, to: StationID{"CD", 3}},
+		{from: StationID{"CD", 3}, to: StationID{"CD", 1}},
+	}
+	g := buildGraph(stations, segments, TravelCostByStop{})
+
+	for _, testCase := range []struct {
+		line     string
+		expected [][]StationID
+	}{
+		{
+			line: "AB",
+			expected: [][]StationID{
+				{{"AB", 1}, {"AB", 2}},
+				{{"AB", 2}, {"AB", 3}, {"AB", 4}},
+				{{"AB", 2}, {"AB", 5}},
+			},
+		},
+		{
+			line:     "CD",
+			expected: [][]StationID{{{"CD", 1}, {"CD", 2}, {"CD", 3}, {"CD", 1}}},
+		},
+		{
+			line:     "EF",
+			expected: [][]StationID{},
+		},
+	} {
+		if actual := lineChains(testCase.line, stations, g); !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("%s expected: %v, actual: %v", testCase.line, testCase.expected, actual)
+		}
+	}
+}
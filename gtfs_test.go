@@ -0,0 +1,223 @@
+package main
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var gtfsFeed = map[string]string{
+	"stops.txt": "stop_id,stop_code,stop_name,stop_lat,stop_lon,location_type\n" +
+		"S1,NS1,Alpha,1.30,103.80,0\n" +
+		"S2,NS2,Bravo,1.31,103.80,0\n" +
+		"S3,NS3,Charlie,1.32,103.80,0\n" +
+		"S4,EW1,Charlie,1.32,103.80,0\n" +
+		"S5,,Delta,1.32,103.81,1\n" +
+		"EW2,,Delta,1.32,103.82,\n",
+	"routes.txt": "route_id,route_short_name,route_type\n" +
+		"R1,NSL,1\n" +
+		"R2,EWL,1\n",
+	"trips.txt": "route_id,service_id,trip_id\n" +
+		"R1,WD,T1\n" +
+		"R1,WD,T2\n" +
+		"R2,WD,T3\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		"T1,08:03:30,08:04:00,S2,2\n" +
+		"T1,08:00:00,08:00:30,S1,1\n" +
+		"T1,08:06:00,08:06:00,S3,3\n" +
+		"T2,09:00:00,09:00:00,S3,1\n" +
+		"T2,09:02:00,09:02:30,S2,2\n" +
+		"T2,09:06:30,09:06:30,S1,3\n" +
+		"T3,24:00:00,24:00:00,S4,1\n" +
+		"T3,24:05:00,24:05:00,EW2,2\n",
+	"transfers.txt": "from_stop_id,to_stop_id,transfer_type,min_transfer_time\n" +
+		"S3,S4,2,150\n" +
+		"S1,S2,2,60\n",
+}
+
+func writeGTFSDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gtfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func writeGTFSZip(t *testing.T, files map[string]string) string {
+	f, err := ioutil.TempFile("", "gtfs*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create("feed/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestReadGTFS(t *testing.T) {
+	dir := writeGTFSDir(t, gtfsFeed)
+	defer os.RemoveAll(dir)
+	zipFile := writeGTFSZip(t, gtfsFeed)
+	defer os.Remove(zipFile)
+
+	ns1, ns2, ns3 := StationID{"NS", 1}, StationID{"NS", 2}, StationID{"NS", 3}
+	ew1, ew2 := StationID{"EW", 1}, StationID{"EW", 2}
+	expected := Network{
+		stations: []Station{
+			Station{id: ns1, name: "Alpha"},
+			Station{id: ns2, name: "Bravo"},
+			Station{id: ns3, name: "Charlie"},
+			Station{id: ew1, name: "Charlie"},
+			Station{id: ew2, name: "Delta"},
+		},
+		segments: []Segment{
+			Segment{from: ns1, to: ns2},
+			Segment{from: ns2, to: ns3},
+			Segment{from: ew1, to: ew2},
+		},
+		segmentTimes: []SegmentTime{
+			SegmentTime{segment: Segment{from: ns1, to: ns2}, minutes: 4},
+			SegmentTime{segment: Segment{from: ns2, to: ns3}, minutes: 2},
+			SegmentTime{segment: Segment{from: ew1, to: ew2}, minutes: 5},
+		},
+		interchangeTimes: []InterchangeTime{
+			InterchangeTime{from: ns3, to: ew1, minutes: 3},
+		},
+		coordinates: map[StationID]Coordinates{
+			ns1: Coordinates{Latitude: 1.30, Longitude: 103.80},
+			ns2: Coordinates{Latitude: 1.31, Longitude: 103.80},
+			ns3: Coordinates{Latitude: 1.32, Longitude: 103.80},
+			ew1: Coordinates{Latitude: 1.32, Longitude: 103.80},
+			ew2: Coordinates{Latitude: 1.32, Longitude: 103.82},
+		},
+	}
+
+	for _, path := range []string{dir, zipFile} {
+		actual, err := ReadGTFS(path)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", path, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s expected: %v, actual: %v", path, expected, actual)
+		}
+	}
+}
+
+func TestReadGTFSError(t *testing.T) {
+	for _, testCase := range []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "missing stop times",
+			files: map[string]string{
+				"stops.txt":  gtfsFeed["stops.txt"],
+				"routes.txt": gtfsFeed["routes.txt"],
+				"trips.txt":  gtfsFeed["trips.txt"],
+			},
+		},
+		{
+			name: "invalid stop code",
+			files: map[string]string{
+				"stops.txt":      "stop_id,stop_name\nS1,Alpha\n",
+				"routes.txt":     gtfsFeed["routes.txt"],
+				"trips.txt":      gtfsFeed["trips.txt"],
+				"stop_times.txt": gtfsFeed["stop_times.txt"],
+			},
+		},
+		{
+			name: "duplicate stop code",
+			files: map[string]string{
+				"stops.txt":      gtfsFeed["stops.txt"] + "S6,NS2,Echo,1.33,103.80,0\n",
+				"routes.txt":     gtfsFeed["routes.txt"],
+				"trips.txt":      gtfsFeed["trips.txt"],
+				"stop_times.txt": gtfsFeed["stop_times.txt"],
+			},
+		},
+		{
+			name: "stop id duplicating a stop code",
+			files: map[string]string{
+				"stops.txt":      gtfsFeed["stops.txt"] + "EW1,,Foxtrot,1.33,103.81,0\n",
+				"routes.txt":     gtfsFeed["routes.txt"],
+				"trips.txt":      gtfsFeed["trips.txt"],
+				"stop_times.txt": gtfsFeed["stop_times.txt"],
+			},
+		},
+		{
+			name: "trip across lines",
+			files: map[string]string{
+				"stops.txt":      gtfsFeed["stops.txt"],
+				"routes.txt":     gtfsFeed["routes.txt"],
+				"trips.txt":      gtfsFeed["trips.txt"],
+				"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT1,08:00:00,08:00:00,S2,1\nT1,08:02:00,08:02:00,S4,2\n",
+			},
+		},
+		{
+			name: "unknown trip",
+			files: map[string]string{
+				"stops.txt":      gtfsFeed["stops.txt"],
+				"routes.txt":     gtfsFeed["routes.txt"],
+				"trips.txt":      gtfsFeed["trips.txt"],
+				"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT9,08:00:00,08:00:00,S1,1\n",
+			},
+		},
+	} {
+		dir := writeGTFSDir(t, testCase.files)
+		if _, err := ReadGTFS(dir); err == nil {
+			t.Errorf("%s expect error", testCase.name)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNavigatorWithGTFS(t *testing.T) {
+	zipFile := writeGTFSZip(t, gtfsFeed)
+	defer os.Remove(zipFile)
+
+	n, err := NewNavigator(WithGTFS(zipFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := n.NavigateByStops("Alpha", "Delta", NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Weight != 4 {
+		t.Errorf("by stops expected weight: 4, actual: %v", routes)
+	}
+
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	routes, err = n.NavigateByTime("Alpha", "Delta", travelTime, NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Weight != 20 {
+		t.Errorf("by time expected weight: 20, actual: %v", routes)
+	}
+
+	if n, err := NewNavigatorFromGTFS(zipFile); err != nil || n.stationCount() != 5 {
+		t.Errorf("expect deprecated NewNavigatorFromGTFS to load 5 stations, actual error: %v", err)
+	}
+}
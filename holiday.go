@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// kinds of Holidays
+const (
+	holidayKindHoliday = "holiday"
+	holidayKindEve     = "eve"
+)
+
+// Holiday is a public holiday, or the eve of one, in Singapore
+type Holiday struct {
+	date time.Time
+	name string
+	kind string
+}
+
+// Calendar holds the Holidays by date in format YYYY-MM-DD, by which the Schedule
+// classifies the time of travel into travel periods
+type Calendar map[string]Holiday
+
+// newCalendar is a helper function to make the Calendar of Holidays, where a holiday
+// takes precedence over an eve on the same date
+func newCalendar(holidays []Holiday) Calendar {
+	calendar := make(Calendar)
+	for _, h := range holidays {
+		key := h.date.Format("2006-01-02")
+		if existing, ok := calendar[key]; ok && existing.kind == holidayKindHoliday {
+			continue
+		}
+		calendar[key] = h
+	}
+	return calendar
+}
+
+// isHoliday checks if the given time is on a public holiday, or on the eve of one from
+// noon
+func (c Calendar) isHoliday(t time.Time) bool {
+	h, ok := c[t.Format("2006-01-02")]
+	return ok && (h.kind == holidayKindHoliday || t.Hour() >= 12)
+}
+
+// covers checks if the Calendar has any Holiday in the year of the given time, so a day
+// of the year without one is known not to be a public holiday
+func (c Calendar) covers(t time.Time) bool {
+	year := t.Format("2006-")
+	for key := range c {
+		if strings.HasPrefix(key, year) {
+			return true
+		}
+	}
+	return false
+}
+
+// coverageWarnings returns the warning that the public holidays of the year are unknown if
+// the Calendar has some but not of the year of the given time, or nil otherwise
+func (c Calendar) coverageWarnings(t time.Time) []string {
+	if len(c) == 0 || c.covers(t) {
+		return nil
+	}
+	return []string{fmt.Sprintf("Public holidays of %d are unknown, so no day is taken as a holiday", t.Year())}
+}
+
+// ReadHolidays reads the public holidays and eves from the given io.Reader.
+// It assumes the format being:
+/*
+Date,Name,Type
+24 January 2020,Chinese New Year's Eve,eve
+25 January 2020,Chinese New Year,holiday
+*/
+func ReadHolidays(r io.Reader) ([]Holiday, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := []Holiday{}
+
+	for _, record := range records {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("record length not 3: %v", record)
+		}
+		date, err := time.Parse("2 January 2006", record[0])
+		if err != nil {
+			return nil, err
+		}
+		switch record[2] {
+		case holidayKindHoliday, holidayKindEve:
+		default:
+			return nil, fmt.Errorf("unknown holiday type %s", record[2])
+		}
+		final = append(final, Holiday{date: date, name: record[1], kind: record[2]})
+	}
+
+	return final, nil
+}
+
+// ReadICalendar reads the public holidays from the all-day events of an iCalendar file,
+// such as those published by the Ministry of Manpower. Each day from DTSTART until the
+// exclusive DTEND is a holiday, or an eve if the SUMMARY ends with "Eve".
+func ReadICalendar(r io.Reader) ([]Holiday, error) {
+	// unfold the content lines continued by leading whitespace
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	final := []Holiday{}
+	var inEvent bool
+	var start, end time.Time
+	var summary string
+	for _, line := range lines {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		// drop the parameters of the property, eg. DTSTART;VALUE=DATE
+		name, value := strings.ToUpper(strings.SplitN(line[:i], ";", 2)[0]), line[i+1:]
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+		case name == "END" && value == "VEVENT":
+			if !inEvent || start.IsZero() {
+				return nil, errors.New("event without DTSTART")
+			}
+			inEvent = false
+			if end.IsZero() {
+				end = start.AddDate(0, 0, 1)
+			}
+			kind := holidayKindHoliday
+			if strings.HasSuffix(strings.ToLower(summary), "eve") {
+				kind = holidayKindEve
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				final = append(final, Holiday{date: d, name: summary, kind: kind})
+			}
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			// the date part of either DATE or DATE-TIME values
+			if len(value) < 8 {
+				return nil, fmt.Errorf("invalid %s %s", name, value)
+			}
+			date, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return nil, err
+			}
+			if name == "DTSTART" {
+				start = date
+			} else {
+				end = date
+			}
+		case inEvent && name == "SUMMARY":
+			summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+		}
+	}
+	if inEvent {
+		return nil, errors.New("event without END")
+	}
+
+	return final, nil
+}
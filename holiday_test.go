@@ -0,0 +1,161 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadHolidays(t *testing.T) {
+	holidays, err := ReadHolidays(strings.NewReader("Date,Name,Type\n24 January 2020,Chinese New Year's Eve,eve\n25 January 2020,Chinese New Year,holiday\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Holiday{
+		Holiday{date: time.Date(2020, 1, 24, 0, 0, 0, 0, time.UTC), name: "Chinese New Year's Eve", kind: holidayKindEve},
+		Holiday{date: time.Date(2020, 1, 25, 0, 0, 0, 0, time.UTC), name: "Chinese New Year", kind: holidayKindHoliday},
+	}
+	if !reflect.DeepEqual(holidays, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, holidays)
+	}
+
+	for _, input := range []string{
+		"Date,Name,Type\n25 January 2020,Chinese New Year\n",
+		"Date,Name,Type\n2020-01-25,Chinese New Year,holiday\n",
+		"Date,Name,Type\n25 January 2020,Chinese New Year,festival\n",
+	} {
+		if _, err := ReadHolidays(strings.NewReader(input)); err == nil {
+			t.Errorf("expect error on %q", input)
+		}
+	}
+}
+
+func TestReadICalendar(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20200125\r\n" +
+		"DTEND;VALUE=DATE:20200127\r\n" +
+		"SUMMARY:Chinese New\r\n" +
+		"  Year\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20201224T000000Z\r\n" +
+		"SUMMARY:Christmas Eve\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	holidays, err := ReadICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Holiday{
+		Holiday{date: time.Date(2020, 1, 25, 0, 0, 0, 0, time.UTC), name: "Chinese New Year", kind: holidayKindHoliday},
+		Holiday{date: time.Date(2020, 1, 26, 0, 0, 0, 0, time.UTC), name: "Chinese New Year", kind: holidayKindHoliday},
+		Holiday{date: time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC), name: "Christmas Eve", kind: holidayKindEve},
+	}
+	if !reflect.DeepEqual(holidays, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, holidays)
+	}
+
+	for _, input := range []string{
+		"BEGIN:VEVENT\nSUMMARY:Christmas Day\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:2020\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:20201225\n",
+	} {
+		if _, err := ReadICalendar(strings.NewReader(input)); err == nil {
+			t.Errorf("expect error on %q", input)
+		}
+	}
+}
+
+func TestCalendarTravelPeriod(t *testing.T) {
+	calendar := newCalendar([]Holiday{
+		Holiday{date: time.Date(2020, 8, 10, 0, 0, 0, 0, time.UTC), name: "National Day (observed)", kind: holidayKindHoliday},
+		Holiday{date: time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC), name: "Christmas Eve", kind: holidayKindEve},
+	})
+	schedule := defaultSchedule()
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time     string
+		expected string
+	}{
+		{"2020-08-10T08:00", periodHoliday},
+		{"2020-08-10T18:30", periodHoliday},
+		{"2020-08-10T23:00", periodNight},
+		{"2020-08-11T08:00", periodPeak},
+		{"2020-12-24T08:00", periodPeak},
+		{"2020-12-24T12:00", periodHoliday},
+		{"2020-12-24T18:30", periodHoliday},
+		{"2020-12-24T22:00", periodNight},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		if actual := schedule.travelPeriod(travelTime, calendar); actual != testCase.expected {
+			t.Errorf("%s expected: %s, actual: %s", testCase.time, testCase.expected, actual)
+		}
+	}
+}
+
+func TestNavigateHoliday(t *testing.T) {
+	n := defaultNavigator()
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time            string
+		dest            string
+		expectedWeight  Weight
+		expectedPeriods []string
+	}{
+		// National Day (observed) on Monday is priced like Saturday instead of peak hours
+		{"2020-08-10T18:30", "HarbourFront", 106, []string{periodHoliday}},
+		{"2020-11-07T18:30", "HarbourFront", 106, []string{periodNonPeak}},
+		{"2020-11-09T18:30", "HarbourFront", 111, []string{periodPeak}},
+		{"2020-11-09T21:50", "HarbourFront", 107, []string{periodNonPeak, periodNight}},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		routes, err := n.NavigateByTime("Jurong East", testCase.dest, travelTime, NavigateOptions{})
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", testCase.time, err)
+			continue
+		}
+		if routes[0].Weight != testCase.expectedWeight || !reflect.DeepEqual(routes[0].Periods, testCase.expectedPeriods) {
+			t.Errorf("%s expected: %d %v, actual: %d %v", testCase.time, testCase.expectedWeight, testCase.expectedPeriods, routes[0].Weight, routes[0].Periods)
+		}
+	}
+}
+
+func TestCalendarCoverage(t *testing.T) {
+	n := defaultNavigator()
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time     string
+		expected []string
+	}{
+		{"2020-11-09T10:00", nil},
+		{"2026-10-19T10:00", nil},
+		{"2027-01-04T10:00", []string{"Public holidays of 2027 are unknown, so no day is taken as a holiday"}},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		routes, err := n.NavigateByTime("Bishan", "Orchard", travelTime, NavigateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(testCase.expected, routes[0].Warnings) {
+			t.Errorf("%s expected warnings: %v, actual: %v", testCase.time, testCase.expected, routes[0].Warnings)
+		}
+
+		report := Validate(defaultSource(), travelTime)
+		covered := true
+		for _, issue := range report.Issues {
+			if issue.Check == "holiday_coverage" {
+				covered = false
+			}
+		}
+		if covered != (testCase.expected == nil) {
+			t.Errorf("%s expect holiday coverage warning: %v, actual: %v", testCase.time, testCase.expected != nil, report.Issues)
+		}
+	}
+
+	if warnings := newCalendar(nil).coverageWarnings(time.Now()); warnings != nil {
+		t.Errorf("expect no warnings without public holidays, actual: %v", warnings)
+	}
+}
@@ -3,15 +3,26 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// maxLimit is the most routes a navigate request may ask for, as each route after the
+// first one runs a search from every stop of the route before it
+const maxLimit = 10
+
 //// v1 navigate by stops
 type navigateV1Request struct {
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	All         bool   `json:"all"`
+	Source        string   `json:"source"`
+	Destination   string   `json:"destination"`
+	Limit         int      `json:"limit"`
+	AvoidStations []string `json:"avoid_stations"`
+	AvoidLines    []string `json:"avoid_lines"`
+	Via           []string `json:"via"`
+	Algorithm     string   `json:"algorithm"`
 }
 
 type navigateV1Response struct {
@@ -22,15 +33,15 @@ type navigateV1Response struct {
 	Instructions      []string `json:"instructions"`
 }
 
-func makeV1Response(paths []Path) []navigateV1Response {
+func makeV1Response(routes []Route) []navigateV1Response {
 	res := []navigateV1Response{}
-	for _, path := range paths {
+	for _, path := range routes {
 		l := len(path.Stops)
 		res = append(res, navigateV1Response{
 			Source:            path.Stops[0].(Station).name,
 			Destination:       path.Stops[l-1].(Station).name,
 			StationsTravelled: l - 1,
-			Route:             makeRoute(path),
+			Route:             makeRoute(path.Path),
 			Instructions:      makeInstructions(path),
 		})
 	}
@@ -45,16 +56,29 @@ func makeRoute(path Path) []string {
 	return r
 }
 
-func makeInstructions(path Path) []string {
+func makeInstructions(route Route) []string {
+	via := make(map[int]bool)
+	for _, i := range route.Via {
+		via[i] = true
+	}
 	r := []string{}
-	for i := 1; i < len(path.Stops); i++ {
-		prev := path.Stops[i-1].(Station)
-		next := path.Stops[i].(Station)
+	if route.WalkToSource > 0 {
+		r = append(r, fmt.Sprintf("Walk %d minutes to %s", route.WalkToSource, route.Stops[0].(Station).name))
+	}
+	for i := 1; i < len(route.Stops); i++ {
+		prev := route.Stops[i-1].(Station)
+		next := route.Stops[i].(Station)
 		if prev.id.line == next.id.line {
 			r = append(r, fmt.Sprintf("Take %s line from %s to %s", prev.id.line, prev.name, next.name))
 		} else {
 			r = append(r, fmt.Sprintf("Change from %s line to %s line", prev.id.line, next.id.line))
 		}
+		if via[i] {
+			r = append(r, fmt.Sprintf("Stop at via station %s", next.name))
+		}
+	}
+	if route.WalkFromDestination > 0 {
+		r = append(r, fmt.Sprintf("Walk %d minutes from %s to destination", route.WalkFromDestination, route.Stops[len(route.Stops)-1].(Station).name))
 	}
 	return r
 }
@@ -68,19 +92,21 @@ func (n *Navigator) handleV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	if nr.Limit > maxLimit {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("limit must not be more than %d", maxLimit))
+		return
+	}
 
 	// run navigator
-	paths, err := n.NavigateByStops(nr.Source, nr.Destination, nr.All)
+	paths, err := n.NavigateByStops(nr.Source, nr.Destination, NavigateOptions{
+		Limit:         nr.Limit,
+		AvoidStations: nr.AvoidStations,
+		AvoidLines:    nr.AvoidLines,
+		Via:           nr.Via,
+		Algorithm:     Algorithm(nr.Algorithm),
+	})
 	if err != nil {
-		switch err {
-		case ErrorSourceNotFound, ErrorDestinationNotFound, ErrorSourceDestinationSame:
-			respondError(w, http.StatusBadRequest, err.Error())
-		case ErrorPathNotFound:
-			respondError(w, http.StatusNotFound, err.Error())
-		default:
-			// unexpected errors
-			respondError(w, http.StatusInternalServerError, err.Error())
-		}
+		respondNavigateError(w, err, n.suggestions(append(append([]string{nr.Source, nr.Destination}, nr.Via...), nr.AvoidStations...)...))
 		return
 	}
 
@@ -89,30 +115,64 @@ func (n *Navigator) handleV1(w http.ResponseWriter, r *http.Request) {
 
 //// v2 navigate by time
 type navigateV2Request struct {
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	Time        string `json:"time"`
-	All         bool   `json:"all"`
+	Source        string   `json:"source"`
+	Destination   string   `json:"destination"`
+	Time          string   `json:"time"`
+	Limit         int      `json:"limit"`
+	Optimize      string   `json:"optimize"`
+	AvoidStations []string `json:"avoid_stations"`
+	AvoidLines    []string `json:"avoid_lines"`
+	Via           []string `json:"via"`
+	Algorithm     string   `json:"algorithm"`
+
+	SourceCoordinates      *coordinatesRequest `json:"source_coordinates"`
+	DestinationCoordinates *coordinatesRequest `json:"destination_coordinates"`
+}
+
+type coordinatesRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// toCoordinates converts the request coordinates, nil if not given
+func (c *coordinatesRequest) toCoordinates() *Coordinates {
+	if c == nil {
+		return nil
+	}
+	return &Coordinates{Latitude: c.Latitude, Longitude: c.Longitude}
 }
 
 type navigateV2Response struct {
-	Source       string   `json:"source"`
-	Destination  string   `json:"destination"`
-	Minutes      int      `json:"minutes"`
-	Route        []string `json:"route"`
-	Instructions []string `json:"instructions"`
+	Source              string   `json:"source"`
+	Destination         string   `json:"destination"`
+	Minutes             int      `json:"minutes"`
+	Interchanges        int      `json:"interchanges"`
+	Stops               int      `json:"stops"`
+	WalkToSource        int      `json:"walk_to_source,omitempty"`
+	WalkFromDestination int      `json:"walk_from_destination,omitempty"`
+	Periods             []string `json:"periods"`
+	Warnings            []string `json:"warnings,omitempty"`
+	Route               []string `json:"route"`
+	Instructions        []string `json:"instructions"`
 }
 
-func makeV2Response(paths []Path) []navigateV2Response {
+func makeV2Response(routes []Route) []navigateV2Response {
 	res := []navigateV2Response{}
-	for _, path := range paths {
+	for _, path := range routes {
 		l := len(path.Stops)
+		interchanges, stops := routeMetrics(path.Path)
 		res = append(res, navigateV2Response{
-			Source:       path.Stops[0].(Station).name,
-			Destination:  path.Stops[l-1].(Station).name,
-			Minutes:      int(path.Weight),
-			Route:        makeRoute(path),
-			Instructions: makeInstructions(path),
+			Source:              path.Stops[0].(Station).name,
+			Destination:         path.Stops[l-1].(Station).name,
+			Minutes:             int(path.Weight),
+			Interchanges:        interchanges,
+			Stops:               stops,
+			WalkToSource:        int(path.WalkToSource),
+			WalkFromDestination: int(path.WalkFromDestination),
+			Periods:             path.Periods,
+			Warnings:            path.Warnings,
+			Route:               makeRoute(path.Path),
+			Instructions:        makeInstructions(path),
 		})
 	}
 	return res
@@ -127,29 +187,299 @@ func (n *Navigator) handleV2(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	if nr.Limit > maxLimit {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("limit must not be more than %d", maxLimit))
+		return
+	}
+
+	t, err := parseTravelTime(nr.Time)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, c := range []*Coordinates{nr.SourceCoordinates.toCoordinates(), nr.DestinationCoordinates.toCoordinates()} {
+		if c != nil && !c.valid() {
+			respondError(w, http.StatusBadRequest, "coordinates out of range")
+			return
+		}
+	}
+
+	// run navigator
+	paths, err := n.NavigateByTime(nr.Source, nr.Destination, t, NavigateOptions{
+		Limit:         nr.Limit,
+		Optimize:      Optimization(nr.Optimize),
+		AvoidStations: nr.AvoidStations,
+		AvoidLines:    nr.AvoidLines,
+		Via:           nr.Via,
+		Algorithm:     Algorithm(nr.Algorithm),
+
+		SourceCoordinates:      nr.SourceCoordinates.toCoordinates(),
+		DestinationCoordinates: nr.DestinationCoordinates.toCoordinates(),
+	})
+	if err != nil {
+		respondNavigateError(w, err, n.suggestionsAt(t, append(append([]string{nr.Source, nr.Destination}, nr.Via...), nr.AvoidStations...)...))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, makeV2Response(paths))
+}
+
+//// reachable stations within minutes
+type reachableRequest struct {
+	Source  string `json:"source"`
+	Time    string `json:"time"`
+	Minutes int    `json:"minutes"`
+}
+
+type reachableResponse struct {
+	Station string   `json:"station"`
+	Name    string   `json:"name"`
+	Minutes int      `json:"minutes"`
+	Route   []string `json:"route"`
+}
+
+func makeReachableResponse(paths []Path) []reachableResponse {
+	res := []reachableResponse{}
+	for _, path := range paths {
+		s := path.Stops[len(path.Stops)-1].(Station)
+		res = append(res, reachableResponse{
+			Station: s.id.String(),
+			Name:    s.name,
+			Minutes: int(path.Weight),
+			Route:   makeRoute(path),
+		})
+	}
+	return res
+}
+
+func (n *Navigator) handleReachable(w http.ResponseWriter, r *http.Request) {
+	// decode body for request
+	rr := reachableRequest{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rr); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	t, err := parseTravelTime(rr.Time)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if rr.Minutes < 0 {
+		respondError(w, http.StatusBadRequest, "minutes must not be negative")
+		return
+	}
+
+	// run navigator
+	paths, err := n.Reachable(rr.Source, t, Weight(rr.Minutes))
+	if err != nil {
+		respondNavigateError(w, err, n.suggestionsAt(t, rr.Source))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, makeReachableResponse(paths))
+}
+
+//// origin-destination matrix
+// maxMatrixStations is the most sources or destinations a matrix request may list, while
+// the full matrix of all the stations is left to the matrix subcommand
+const maxMatrixStations = 20
+
+type matrixRequest struct {
+	Sources      []string `json:"sources"`
+	Destinations []string `json:"destinations"`
+	Time         string   `json:"time"`
+	Format       string   `json:"format"`
+}
+
+func (n *Navigator) handleMatrix(w http.ResponseWriter, r *http.Request) {
+	// decode body for request
+	mr := matrixRequest{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&mr); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
 
-	t, err := time.Parse("2006-01-02T15:04", nr.Time)
+	t, err := parseTravelTime(mr.Time)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if mr.Format != "" && mr.Format != "json" && mr.Format != "csv" {
+		respondError(w, http.StatusBadRequest, "unknown format")
+		return
+	}
+	if len(mr.Sources) == 0 || len(mr.Destinations) == 0 {
+		respondError(w, http.StatusBadRequest, "sources and destinations are required")
+		return
+	}
+	if len(mr.Sources) > maxMatrixStations || len(mr.Destinations) > maxMatrixStations {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("sources and destinations must not be more than %d each", maxMatrixStations))
+		return
+	}
 
 	// run navigator
-	paths, err := n.NavigateByTime(nr.Source, nr.Destination, t, nr.All)
+	cells, err := n.Matrix(mr.Sources, mr.Destinations, t)
 	if err != nil {
-		switch err {
-		case ErrorSourceNotFound, ErrorDestinationNotFound, ErrorSourceDestinationSame:
+		respondNavigateError(w, err, n.suggestionsAt(t, append(append([]string{}, mr.Sources...), mr.Destinations...)...))
+		return
+	}
+
+	if mr.Format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		// the status is sent already, so a failed write can only be logged
+		if err := WriteMatrixCSV(w, cells); err != nil {
+			log.Printf("failed to write matrix csv: %v", err)
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, cells)
+}
+
+//// nearby stations
+type nearbyResponse struct {
+	Name           string   `json:"name"`
+	Stations       []string `json:"stations"`
+	Meters         int      `json:"meters"`
+	WalkingMinutes int      `json:"walking_minutes"`
+}
+
+func makeNearbyResponse(nearby []NearbyStation) []nearbyResponse {
+	res := []nearbyResponse{}
+	for _, s := range nearby {
+		ids := []string{}
+		for _, id := range s.IDs {
+			ids = append(ids, id.String())
+		}
+		res = append(res, nearbyResponse{
+			Name:           s.Name,
+			Stations:       ids,
+			Meters:         int(math.Round(s.Meters)),
+			WalkingMinutes: int(s.Walking),
+		})
+	}
+	return res
+}
+
+func (n *Navigator) handleNearby(w http.ResponseWriter, r *http.Request) {
+	// parse query parameters, eg. ?lat=1.3048&lon=103.8318&k=3
+	query := r.URL.Query()
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !(Coordinates{Latitude: lat, Longitude: lon}).valid() {
+		respondError(w, http.StatusBadRequest, "coordinates out of range")
+		return
+	}
+	k := 5
+	if query.Get("k") != "" {
+		if k, err = strconv.Atoi(query.Get("k")); err != nil {
 			respondError(w, http.StatusBadRequest, err.Error())
-		case ErrorPathNotFound:
-			respondError(w, http.StatusNotFound, err.Error())
-		default:
-			// unexpected errors
-			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if k <= 0 {
+			respondError(w, http.StatusBadRequest, "k must be positive")
+			return
 		}
+	}
+
+	respondJSON(w, http.StatusOK, makeNearbyResponse(n.NearestStations(lat, lon, k)))
+}
+
+// reloadResponse is the response of admin API to reload the network
+type reloadResponse struct {
+	Stations int `json:"stations"`
+}
+
+// handleReload reloads the network of the Navigator from its data source on POST
+func (n *Navigator) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, makeV2Response(paths))
+	if err := n.Reload(); err != nil {
+		// the current network is kept
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reloadResponse{Stations: n.stationCount()})
+}
+
+// errorResponse is the error response with "did you mean" suggestions of station names
+// for each input matching no station, and the opening dates of inputs matching stations
+// not yet open at the time of travel
+type errorResponse struct {
+	Error      string              `json:"error"`
+	DidYouMean map[string][]string `json:"did_you_mean,omitempty"`
+	NotYetOpen map[string]string   `json:"not_yet_open,omitempty"`
+}
+
+// suggestions is a helper function to suggest station names among all stations for each
+// input matching no station, see Navigator.Suggest
+func (n *Navigator) suggestions(inputs ...string) errorResponse {
+	res := errorResponse{DidYouMean: make(map[string][]string)}
+	for _, input := range inputs {
+		if input == "" {
+			continue
+		}
+		if suggested := n.Suggest(input); len(suggested) > 0 {
+			res.DidYouMean[input] = suggested
+		}
+	}
+	return res
+}
+
+// suggestionsAt is a helper function to suggest station names among the stations open at
+// the time for each input matching no station, or tell the opening date of the station
+// it matches instead, see Navigator.SuggestAt
+func (n *Navigator) suggestionsAt(t time.Time, inputs ...string) errorResponse {
+	res := errorResponse{DidYouMean: make(map[string][]string), NotYetOpen: make(map[string]string)}
+	for _, input := range inputs {
+		if input == "" {
+			continue
+		}
+		suggested, opening := n.SuggestAt(input, t)
+		if len(suggested) > 0 {
+			res.DidYouMean[input] = suggested
+		}
+		if !opening.IsZero() {
+			res.NotYetOpen[input] = opening.Format("2006-01-02")
+		}
+	}
+	return res
+}
+
+// respondNavigateError makes the error response for errors returned by Navigator, with
+// the suggestions of station names if stations are not found
+func respondNavigateError(w http.ResponseWriter, err error, suggestions errorResponse) {
+	switch err {
+	case ErrorSourceNotFound, ErrorDestinationNotFound, ErrorAvoidNotFound, ErrorViaNotFound:
+		suggestions.Error = err.Error()
+		respondJSON(w, http.StatusBadRequest, suggestions)
+	case ErrorSourceDestinationSame,
+		ErrorUnknownOptimization, ErrorSourceAvoided, ErrorDestinationAvoided,
+		ErrorViaAvoided, ErrorViaPareto, ErrorUnknownAlgorithm, ErrorUnsupportedAlgorithm:
+		respondError(w, http.StatusBadRequest, err.Error())
+	case ErrorPathNotFound, ErrorAvoidDisconnected:
+		respondError(w, http.StatusNotFound, err.Error())
+	default:
+		// unexpected errors
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
 }
 
 // respondJSON makes the response with payload as json format
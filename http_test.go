@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleNearby(t *testing.T) {
+	n := defaultNavigator()
+	for _, testCase := range []struct {
+		query    string
+		expected int
+	}{
+		{"lat=1.3048&lon=103.8318", http.StatusOK},
+		{"lat=1.3048&lon=103.8318&k=1", http.StatusOK},
+		{"lat=1.3048", http.StatusBadRequest},
+		{"lat=1.3048&lon=103.8318&k=0", http.StatusBadRequest},
+		{"lat=1.3048&lon=103.8318&k=-3", http.StatusBadRequest},
+		{"lat=91&lon=103.8318", http.StatusBadRequest},
+		{"lat=1.3048&lon=-180.5", http.StatusBadRequest},
+		{"lat=NaN&lon=103.8318", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		n.handleNearby(w, httptest.NewRequest(http.MethodGet, "/api/stations/nearby?"+testCase.query, nil))
+		if w.Code != testCase.expected {
+			t.Errorf("%s expected status: %d, actual: %d %s", testCase.query, testCase.expected, w.Code, w.Body)
+		}
+	}
+}
+
+func TestHandleNavigateSuggestions(t *testing.T) {
+	n := defaultNavigator()
+	for _, testCase := range []struct {
+		name     string
+		handler  http.HandlerFunc
+		body     string
+		expected string
+	}{
+		{
+			"v1 typo",
+			n.handleV1,
+			`{"source":"Dhoby Gaut","destination":"Orchard"}`,
+			`{"error":"source not found","did_you_mean":{"Dhoby Gaut":["Dhoby Ghaut"]}}`,
+		},
+		{
+			"v1 typo of a station opening later",
+			n.handleV1,
+			`{"source":"Orchard Boulevrd","destination":"Orchard","limit":1}`,
+			`{"error":"source not found","did_you_mean":{"Orchard Boulevrd":["Orchard Boulevard"]}}`,
+		},
+		{
+			"v2 typo of a station not yet open",
+			n.handleV2,
+			`{"source":"Orchard Boulevrd","destination":"Dhoby Gaut","time":"2020-11-09T08:00"}`,
+			`{"error":"source not found","did_you_mean":{"Dhoby Gaut":["Dhoby Ghaut"]}}`,
+		},
+		{
+			"v2 station not yet open",
+			n.handleV2,
+			`{"source":"Orchard Boulevard","destination":"Orchard","time":"2020-11-09T08:00"}`,
+			`{"error":"source not found","not_yet_open":{"Orchard Boulevard":"2021-12-31"}}`,
+		},
+	} {
+		w := httptest.NewRecorder()
+		testCase.handler(w, httptest.NewRequest(http.MethodGet, "/", strings.NewReader(testCase.body)))
+		if w.Code != http.StatusBadRequest || w.Body.String() != testCase.expected {
+			t.Errorf("%s expected: %d %s, actual: %d %s", testCase.name, http.StatusBadRequest, testCase.expected, w.Code, w.Body)
+		}
+	}
+}
+
+func TestHandleNavigateLimit(t *testing.T) {
+	n := defaultNavigator()
+	for _, testCase := range []struct {
+		name     string
+		handler  http.HandlerFunc
+		body     string
+		expected int
+	}{
+		{"v1 at the maximum", n.handleV1, `{"source":"Bishan","destination":"Orchard","limit":10}`, http.StatusOK},
+		{"v1 above the maximum", n.handleV1, `{"source":"Bishan","destination":"Orchard","limit":11}`, http.StatusBadRequest},
+		{"v2 at the maximum", n.handleV2, `{"source":"Bishan","destination":"Orchard","time":"2020-11-09T08:00","limit":10}`, http.StatusOK},
+		{"v2 above the maximum", n.handleV2, `{"source":"Bishan","destination":"Orchard","time":"2020-11-09T08:00","limit":100000}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		testCase.handler(w, httptest.NewRequest(http.MethodGet, "/", strings.NewReader(testCase.body)))
+		if w.Code != testCase.expected {
+			t.Errorf("%s expected status: %d, actual: %d %s", testCase.name, testCase.expected, w.Code, w.Body)
+		}
+	}
+}
+
+func TestHandleMatrix(t *testing.T) {
+	n := defaultNavigator()
+	tooMany := strings.Repeat(`"Bishan",`, maxMatrixStations) + `"Orchard"`
+	for _, testCase := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"sources":["Bishan"],"destinations":["Orchard","Jurong East"],"time":"2020-11-09T08:00"}`, http.StatusOK},
+		{`{"sources":["Bishan"],"destinations":["Orchard"],"time":"2020-11-09T08:00","format":"csv"}`, http.StatusOK},
+		{`{"time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+		{`{"sources":["Bishan"],"time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+		{`{"sources":[` + tooMany + `],"destinations":["Orchard"],"time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+		{`{"sources":["Bishan"],"destinations":[` + tooMany + `],"time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		n.handleMatrix(w, httptest.NewRequest(http.MethodGet, "/api/matrix", strings.NewReader(testCase.body)))
+		if w.Code != testCase.expected {
+			t.Errorf("%s expected status: %d, actual: %d %s", testCase.body, testCase.expected, w.Code, w.Body)
+		}
+	}
+}
+
+func TestHandleV2Coordinates(t *testing.T) {
+	n := defaultNavigator()
+	for _, testCase := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"source_coordinates":{"latitude":1.3048,"longitude":103.8318},"destination":"Bishan","time":"2020-11-09T08:00"}`, http.StatusOK},
+		{`{"source_coordinates":{"latitude":91,"longitude":103.8318},"destination":"Bishan","time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+		{`{"source":"Bishan","destination_coordinates":{"latitude":1.3048,"longitude":-180.5},"time":"2020-11-09T08:00"}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		n.handleV2(w, httptest.NewRequest(http.MethodGet, "/api/navigate/v2", strings.NewReader(testCase.body)))
+		if w.Code != testCase.expected || (w.Code == http.StatusBadRequest && !strings.Contains(w.Body.String(), "coordinates out of range")) {
+			t.Errorf("%s expected status: %d, actual: %d %s", testCase.body, testCase.expected, w.Code, w.Body)
+		}
+	}
+}
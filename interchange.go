@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// InterchangeTime is the walking time in minutes between two Stations with the same
+// name on different lines, in either direction. An empty period means the time applies
+// to all travel periods.
+type InterchangeTime struct {
+	from    StationID
+	to      StationID
+	period  string
+	minutes Weight
+}
+
+// ReadInterchangeTimes reads the walking times of interchanges from the given io.Reader.
+// It assumes the format being:
+/*
+From,To,Period,Minutes
+NS24,CC1,,6
+NS24,NE6,peak,9
+*/
+func ReadInterchangeTimes(r io.Reader) ([]InterchangeTime, error) {
+	records, err := readStationPairTimes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	final := []InterchangeTime{}
+
+	for _, record := range records {
+		if record.from.line == record.to.line {
+			return nil, fmt.Errorf("interchange %s-%s on the same line", record.from, record.to)
+		}
+		final = append(final, InterchangeTime{
+			from:    record.from,
+			to:      record.to,
+			period:  record.period,
+			minutes: record.minutes,
+		})
+	}
+
+	return final, nil
+}
+
+// validateInterchangeTimes checks that every walking time belongs to an interchange,
+// ie. a pair of known Stations with the same name.
+func validateInterchangeTimes(stations []Station, times []InterchangeTime) error {
+	names := make(map[StationID]string)
+	for _, s := range stations {
+		names[s.id] = s.name
+	}
+	for _, it := range times {
+		from, ok := names[it.from]
+		if !ok {
+			return fmt.Errorf("interchange time references unknown station %s", it.from)
+		}
+		to, ok := names[it.to]
+		if !ok {
+			return fmt.Errorf("interchange time references unknown station %s", it.to)
+		}
+		if from != to {
+			return fmt.Errorf("%s %s and %s %s is not an interchange", it.from, from, it.to, to)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadInterchangeTimes(t *testing.T) {
+	fileContent := "From,To,Period,Minutes\n" +
+		"NS24,CC1,,6\n" +
+		"NS24,NE6,peak,9\n"
+	expected := []InterchangeTime{
+		InterchangeTime{
+			from:    StationID{line: "NS", number: 24},
+			to:      StationID{line: "CC", number: 1},
+			minutes: 6,
+		},
+		InterchangeTime{
+			from:    StationID{line: "NS", number: 24},
+			to:      StationID{line: "NE", number: 6},
+			period:  periodPeak,
+			minutes: 9,
+		},
+	}
+	actual, err := ReadInterchangeTimes(strings.NewReader(fileContent))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestReadInterchangeTimesError(t *testing.T) {
+	for _, fileContent := range []string{
+		"",
+		"From,To,Period,Minutes\nNS24,CC1,6\n",
+		"From,To,Period,Minutes\nNS24,NS25,,6\n",
+	} {
+		_, err := ReadInterchangeTimes(strings.NewReader(fileContent))
+		if err == nil {
+			t.Errorf("expect error for input: %q", fileContent)
+		}
+	}
+}
+
+func TestValidateInterchangeTimes(t *testing.T) {
+	stations := []Station{
+		Station{id: StationID{line: "NS", number: 24}, name: "Dhoby Ghaut"},
+		Station{id: StationID{line: "CC", number: 1}, name: "Dhoby Ghaut"},
+		Station{id: StationID{line: "CC", number: 2}, name: "Bras Basah"},
+	}
+	for _, testCase := range []struct {
+		time        InterchangeTime
+		expectError bool
+	}{
+		{
+			time: InterchangeTime{from: StationID{line: "NS", number: 24}, to: StationID{line: "CC", number: 1}},
+		},
+		{
+			time:        InterchangeTime{from: StationID{line: "NS", number: 24}, to: StationID{line: "CC", number: 2}},
+			expectError: true,
+		},
+		{
+			time:        InterchangeTime{from: StationID{line: "NE", number: 6}, to: StationID{line: "CC", number: 1}},
+			expectError: true,
+		},
+	} {
+		err := validateInterchangeTimes(stations, []InterchangeTime{testCase.time})
+		if testCase.expectError && err == nil {
+			t.Errorf("expect error on %v", testCase.time)
+		}
+		if !testCase.expectError && err != nil {
+			t.Errorf("not expect error on %v: %s", testCase.time, err)
+		}
+	}
+}
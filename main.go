@@ -1,17 +1,75 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	navigator := NewNavigator()
+	dataDir := flag.String("data", os.Getenv("MRT_DATA_DIR"), "directory of the network csv files, embedded ./data if empty (env MRT_DATA_DIR)")
+	gtfs := flag.String("gtfs", os.Getenv("MRT_GTFS"), "GTFS feed zip file or directory of the network, overrides -data (env MRT_GTFS)")
+	adminAddr := os.Getenv("MRT_ADMIN_ADDR")
+	if adminAddr == "" {
+		adminAddr = "localhost:8081"
+	}
+	flag.StringVar(&adminAddr, "admin", adminAddr, "address of the admin server, disabled if empty (env MRT_ADMIN_ADDR)")
+	flag.Parse()
+
+	opts := []NavigatorOption{}
+	switch {
+	case *gtfs != "":
+		opts = append(opts, WithGTFS(*gtfs))
+	case *dataDir != "":
+		opts = append(opts, WithDataDir(*dataDir))
+	}
+
+	if args := flag.Args(); len(args) > 0 && commands[args[0]] != nil {
+		if err := commands[args[0]](args[1:], os.Stdout, opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	navigator, err := NewNavigator(opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
 	httpPort := ":8080"
 
+	// reload the network on SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			if err := navigator.Reload(); err != nil {
+				log.Printf("reload failed, keeping the current network: %v", err)
+				continue
+			}
+			log.Printf("reloaded network of %d stations", navigator.stationCount())
+		}
+	}()
+
 	http.HandleFunc("/api/navigate/v1", navigator.handleV1)
 	http.HandleFunc("/api/navigate/v2", navigator.handleV2)
+	http.HandleFunc("/api/reachable", navigator.handleReachable)
+	http.HandleFunc("/api/matrix", navigator.handleMatrix)
+	http.HandleFunc("/api/stations/nearby", navigator.handleNearby)
+
+	// the admin API is served apart from the public one, only on localhost by default
+	if adminAddr != "" {
+		admin := http.NewServeMux()
+		admin.HandleFunc("/admin/reload", navigator.handleReload)
+		go func() {
+			fmt.Printf("Admin listening on %s\n", adminAddr)
+			log.Fatal(http.ListenAndServe(adminAddr, admin))
+		}()
+	}
 
 	fmt.Printf("Listening on %s\n", httpPort)
 	log.Fatal(http.ListenAndServe(httpPort, nil))
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MatrixCell is the fastest journey from a source to a destination of an
+// origin-destination matrix. Minutes, Interchanges and Stops are zero when the
+// destination is not reachable.
+type MatrixCell struct {
+	Source       string `json:"source"`
+	Destination  string `json:"destination"`
+	Reachable    bool   `json:"reachable"`
+	Minutes      int    `json:"minutes"`
+	Interchanges int    `json:"interchanges"`
+	Stops        int    `json:"stops"`
+}
+
+// Matrix returns the fastest journeys from every source to every destination knowing the
+// time of travel, or any error encountered. The time of travel is considered like
+// NavigateByTime. It accepts sources and destinations as StationIDs like "DT1" or station
+// names like "Bukit Panjang", and all the names of Stations opened at the time of travel
+// are used when they are empty. A single search is run for each source, and the cells are
+// ordered by source then destination.
+func (n *Navigator) Matrix(sources, destinations []string, t time.Time) ([]MatrixCell, error) {
+	t = t.In(networkLocation)
+	c := n.graphAt(t)
+
+	if len(sources) == 0 {
+		sources = stationNames(c.stations)
+	}
+	if len(destinations) == 0 {
+		destinations = stationNames(c.stations)
+	}
+
+	allDest := make([][]StationID, len(destinations))
+	for i, destStr := range destinations {
+		ids, _, err := searchStations(c.stations, c.aliases, destStr)
+		if err != nil {
+			return nil, ErrorDestinationNotFound
+		}
+		allDest[i] = ids
+	}
+
+	cells := []MatrixCell{}
+	for _, srcStr := range sources {
+		paths, err := c.reachable(srcStr, t, -1)
+		if err != nil {
+			return nil, err
+		}
+		// reachable keeps the fastest path to each Station
+		fastest := make(map[StationID]Path)
+		for _, p := range paths {
+			fastest[p.Stops[len(p.Stops)-1].(Station).id] = p
+		}
+
+		for i, destStr := range destinations {
+			cell := MatrixCell{Source: srcStr, Destination: destStr}
+			for _, id := range allDest[i] {
+				p, ok := fastest[id]
+				if !ok || (cell.Reachable && Weight(cell.Minutes) <= p.Weight) {
+					continue
+				}
+				interchanges, stops := routeMetrics(p)
+				cell.Reachable, cell.Minutes, cell.Interchanges, cell.Stops = true, int(p.Weight), interchanges, stops
+			}
+			cells = append(cells, cell)
+		}
+	}
+	return cells, nil
+}
+
+// stationNames is a helper function to list the distinct names of Stations in order
+func stationNames(stations []Station) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, s := range stations {
+		if !seen[s.name] {
+			seen[s.name] = true
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// WriteMatrixCSV writes the cells of an origin-destination matrix to the given io.Writer.
+// It writes the format being:
+/*
+Source,Destination,Minutes,Interchanges,Stops
+Bishan,Orchard,36,0,5
+*/
+// where the cells of unreachable destinations are left empty.
+func WriteMatrixCSV(w io.Writer, cells []MatrixCell) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"Source", "Destination", "Minutes", "Interchanges", "Stops"}); err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		record := []string{cell.Source, cell.Destination, "", "", ""}
+		if cell.Reachable {
+			record[2] = strconv.Itoa(cell.Minutes)
+			record[3] = strconv.Itoa(cell.Interchanges)
+			record[4] = strconv.Itoa(cell.Stops)
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// WriteMatrixJSON writes the cells of an origin-destination matrix to the given io.Writer
+// as a json array.
+func WriteMatrixJSON(w io.Writer, cells []MatrixCell) error {
+	return json.NewEncoder(w).Encode(cells)
+}
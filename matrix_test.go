@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatrix(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+
+	cells, err := defaultNavigator().Matrix([]string{"Bishan", "NS1"}, []string{"Orchard", "Bishan"}, travelTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []MatrixCell{
+		MatrixCell{Source: "Bishan", Destination: "Orchard", Reachable: true, Minutes: 62, Interchanges: 0, Stops: 5},
+		MatrixCell{Source: "Bishan", Destination: "Bishan", Reachable: true, Minutes: 0, Interchanges: 0, Stops: 0},
+		MatrixCell{Source: "NS1", Destination: "Orchard", Reachable: true, Minutes: 134, Interchanges: 4, Stops: 9},
+		MatrixCell{Source: "NS1", Destination: "Bishan", Reachable: true, Minutes: 112, Interchanges: 2, Stops: 9},
+	}
+	if !reflect.DeepEqual(cells, expected) {
+		t.Errorf("expected: %v\n  actual: %v", expected, cells)
+	}
+
+	// every single-source search agrees with NavigateByTime
+	for _, cell := range cells {
+		if cell.Source == cell.Destination {
+			continue
+		}
+		routes, err := defaultNavigator().NavigateByTime(cell.Source, cell.Destination, travelTime, NavigateOptions{})
+		if err != nil || int(routes[0].Weight) != cell.Minutes {
+			t.Errorf("%s to %s expected minutes: %d, actual: %v, %v", cell.Source, cell.Destination, cell.Minutes, routes, err)
+		}
+	}
+
+	if _, err := defaultNavigator().Matrix([]string{"???"}, nil, travelTime); err != ErrorSourceNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorSourceNotFound, err)
+	}
+	if _, err := defaultNavigator().Matrix(nil, []string{"???"}, travelTime); err != ErrorDestinationNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorDestinationNotFound, err)
+	}
+}
+
+func TestMatrixAllStations(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+
+	cells, err := defaultNavigator().Matrix(nil, nil, travelTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 122 distinct names of the stations opened in Nov 2020
+	if len(cells) != 122*122 {
+		t.Errorf("expected cells: %d, actual: %d", 122*122, len(cells))
+	}
+	for _, cell := range cells {
+		if !cell.Reachable {
+			t.Errorf("%s to %s expected reachable", cell.Source, cell.Destination)
+		}
+	}
+}
+
+func TestWriteMatrixCSV(t *testing.T) {
+	cells := []MatrixCell{
+		MatrixCell{Source: "Bishan", Destination: "Orchard", Reachable: true, Minutes: 60, Interchanges: 0, Stops: 5},
+		MatrixCell{Source: "Bishan", Destination: "Lentor"},
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteMatrixCSV(buf, cells); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Source,Destination,Minutes,Interchanges,Stops\nBishan,Orchard,60,0,5\nBishan,Lentor,,,\n"
+	if buf.String() != expected {
+		t.Errorf("expected: %q, actual: %q", expected, buf.String())
+	}
+}
@@ -1,129 +1,415 @@
 package main
 
 import (
+	"errors"
 	"sort"
+	"sync"
 	"time"
 )
 
-// Navigator holds a map of all Stations and provides multiple navigating methods
+// Navigator holds a map of all Stations with the line topology and provides multiple
+// navigating methods. The Graphs built for navigating are cached, and it is safe for
+// concurrent use.
 type Navigator struct {
-	allStations []Station
+	mu           sync.Mutex
+	allStations  []Station
+	segments     []Segment
+	travelCosts  map[string]TravelCost
+	coordinates  map[StationID]Coordinates
+	aliases      map[string]string
+	calendar     Calendar
+	schedule     Schedule
+	services     serviceTimetable
+	openingDates []time.Time
+	graphs       map[graphKey]cachedGraph
+	source       StationSource
 }
 
-// NewNavigator loads all Stations and returns a Navigator instance
-func NewNavigator() *Navigator {
-	return &Navigator{
-		allStations: loadAllStations(),
+// NewNavigator loads all Stations, Segments with their running times, interchange walking
+// times and station coordinates from the StationSource set by the options, or the csv
+// files embedded from ./data by default, and returns a Navigator instance or any error
+// encountered when loading or validating them
+func NewNavigator(opts ...NavigatorOption) (*Navigator, error) {
+	config := newNavigatorConfig(opts)
+	network, err := config.source.Network()
+	if err != nil {
+		return nil, err
+	}
+	if err := network.validate(); err != nil {
+		return nil, err
 	}
+	n := newNavigator(network)
+	n.source = config.source
+	return n, nil
+}
+
+// newNavigator returns a Navigator instance of the Network
+func newNavigator(network Network) *Navigator {
+	n := &Navigator{}
+	n.setNetwork(network)
+	return n
+}
+
+// NavigateOptions customises the routes searched by Navigator
+type NavigateOptions struct {
+	// Limit is the maximum number of distinct routes returned, only the best if less than 2
+	Limit int
+	// Optimize is the objective of NavigateByTime, OptimizeTime if empty
+	Optimize Optimization
+	// AvoidStations are the StationIDs or station names removed before searching
+	AvoidStations []string
+	// AvoidLines are the line codes whose Stations are removed before searching
+	AvoidLines []string
+	// Via are the StationIDs or station names to pass through in order
+	Via []string
+	// Algorithm searches the best route when Limit is less than 2, AlgorithmDijkstra if empty
+	Algorithm Algorithm
+	// SourceCoordinates replaces the source by the nearest Station to walk from if not nil
+	SourceCoordinates *Coordinates
+	// DestinationCoordinates replaces the destination by the nearest Station to walk to if not nil
+	DestinationCoordinates *Coordinates
+}
+
+// Route is a Path found by Navigator, knowing where it passes through the via stations
+type Route struct {
+	Path
+	// Via are the indices of Stops at the via stations
+	Via []int
+	// WalkToSource is the minutes walking from the source Coordinates to the first Station
+	WalkToSource Weight
+	// WalkFromDestination is the minutes walking from the last Station to the destination Coordinates
+	WalkFromDestination Weight
+	// Periods are the travel periods applied along the route in order, only by NavigateByTime
+	Periods []string
+	// Warnings are about boardings close to the last train and public holidays unknown for
+	// the year of travel, only by NavigateByTime
+	Warnings []string
 }
 
-// NavigateByStops returns shortest paths between two Stations or any error encountered.
+// Optimization is the objective of route searching
+type Optimization string
+
+const (
+	// OptimizeTime searches the fastest routes
+	OptimizeTime Optimization = "time"
+	// OptimizePareto searches the Pareto-optimal routes over minutes, interchanges and
+	// stops, ie. every route returned is better than the others in one of them
+	OptimizePareto Optimization = "pareto"
+)
+
+// Algorithm is the path-finding algorithm searching the best route
+type Algorithm string
+
+const (
+	// AlgorithmDijkstra searches with Graph.BFS by stops, or Graph.TimeDependentDijkstra by time
+	AlgorithmDijkstra Algorithm = "dijkstra"
+	// AlgorithmBidirectional searches with Graph.BidirectionalDijkstra, only by stops
+	AlgorithmBidirectional Algorithm = "bidirectional"
+	// AlgorithmAStar searches with Graph.TimeDependentAStar and the landmarks Heuristic
+	AlgorithmAStar Algorithm = "astar"
+)
+
+// ErrorUnknownAlgorithm is returned by Navigator when the algorithm is not supported.
+var ErrorUnknownAlgorithm = errors.New("unknown algorithm")
+
+// ErrorUnsupportedAlgorithm is returned by NavigateByTime when the algorithm cannot search
+// with time-dependent costs.
+var ErrorUnsupportedAlgorithm = errors.New("algorithm not supported for time-dependent search")
+
+// ErrorUnknownOptimization is returned by Navigator when the objective is not supported.
+var ErrorUnknownOptimization = errors.New("unknown optimization")
+
+// ErrorViaNotFound is returned by Navigator when a via station does not exist.
+var ErrorViaNotFound = errors.New("via station not found")
+
+// ErrorViaAvoided is returned by Navigator when a via station is avoided.
+var ErrorViaAvoided = errors.New("via station avoided")
+
+// ErrorViaPareto is returned by Navigator when via stations are given with OptimizePareto.
+var ErrorViaPareto = errors.New("via stations not supported with pareto optimization")
+
+// NavigateByStops returns shortest routes between two Stations or any error encountered.
 // It accepts source and destination input as string, which can be either StationID like "DT1"
-// or station name like "Bukit Panjang". If all is set to true, all the paths ordered by
-// number of stops are returned instead just the shortest.
-func (n *Navigator) NavigateByStops(srcStr, destStr string, all bool) ([]Path, error) {
-	allSrc, srcIsID, err := searchStations(n.allStations, srcStr)
+// or station name like "Bukit Panjang". Up to opts.Limit distinct routes ordered by number of
+// stops are returned, or the single route through the via stations when opts.Via is given.
+// The minutes walking from or to Coordinates are reported but not counted as stops.
+func (n *Navigator) NavigateByStops(srcStr, destStr string, opts NavigateOptions) ([]Route, error) {
+	if err := checkAlgorithm(opts.Algorithm, false); err != nil {
+		return nil, err
+	}
+	c := n.graphByStops()
+	srcStr, destStr, walkTo, walkFrom, err := c.nearestToCoordinates(srcStr, destStr, opts)
 	if err != nil {
-		return nil, ErrorSourceNotFound
+		return nil, err
 	}
-	allDest, destIsID, err := searchStations(n.allStations, destStr)
+
+	routes, err := navigateVia(c, srcStr, destStr, opts, func(srcStr, destStr string, opts NavigateOptions, _ Weight) ([]Path, error) {
+		paths, err := navigate(c, srcStr, destStr, opts, func(c cachedGraph, src, dest StationID, accept PathFilter) ([]Path, error) {
+			return c.search(src, dest, opts, unitWeight, false, accept)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return limitPaths(paths, opts.Limit), nil
+	})
 	if err != nil {
-		return nil, ErrorDestinationNotFound
+		return nil, err
 	}
+	return withWalking(routes, walkTo, walkFrom, false), nil
+}
 
-	g := buildGraph(n.allStations, TravelCostByStop{})
+// NavigateByTime returns fastest routes between two Stations or any error encountered, knowing the
+// time of travel. The cost of each part of the journey is evaluated at the time it is reached.
+// It accepts source and destination input as string, which can be either StationID like "DT1"
+// or station name like "Bukit Panjang". Up to opts.Limit distinct routes ordered by estimated
+// time are returned, or all the Pareto-optimal routes when optimizing with OptimizePareto, or
+// the single route through the via stations when opts.Via is given.
+func (n *Navigator) NavigateByTime(srcStr, destStr string, t time.Time, opts NavigateOptions) ([]Route, error) {
+	if err := checkAlgorithm(opts.Algorithm, true); err != nil {
+		return nil, err
+	}
+	// decide the timing in local time of the network
+	t = t.In(networkLocation)
+	// get opening stations at the time of travel
+	c := n.graphAt(t)
+	srcStr, destStr, walkTo, walkFrom, err := c.nearestToCoordinates(srcStr, destStr, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	paths := []Path{}
+	routes, err := navigateVia(c, srcStr, destStr, opts, func(srcStr, destStr string, opts NavigateOptions, at Weight) ([]Path, error) {
+		// each leg departs when the previous one arrives, after walking to the source
+		departure := t.Add(time.Duration(walkTo+at) * time.Minute)
 
-	for _, src := range allSrc {
-		for _, dest := range allDest {
-			ps, err := g.UnweightedSearch(src, dest, all)
+		switch opts.Optimize {
+		case "", OptimizeTime:
+			paths, err := navigate(c, srcStr, destStr, opts, func(c cachedGraph, src, dest StationID, accept PathFilter) ([]Path, error) {
+				return c.search(src, dest, opts, c.timeDependentWeight(departure, src, dest, at > 0), true, accept)
+			})
 			if err != nil {
-				continue
+				return nil, err
 			}
-			for _, p := range ps {
-				l := len(p.Stops)
-				if l < 2 {
-					continue
-				}
-				// filter out paths that start interchanging when source is not pinned to an ID
-				if !srcIsID && p.Stops[0].(Station).name == p.Stops[1].(Station).name {
-					continue
+			return limitPaths(paths, opts.Limit), nil
+		case OptimizePareto:
+			paths, err := navigate(c, srcStr, destStr, opts, func(c cachedGraph, src, dest StationID, _ PathFilter) ([]Path, error) {
+				ps, err := c.graph.ParetoPaths(src, dest, Criteria{0, 0, 0}, c.timeDependentCriteria(departure, src, dest, at > 0))
+				if err != nil {
+					return nil, err
 				}
-				// filter out paths that end interchanging when destination is not pinned to an ID
-				if !destIsID && p.Stops[l-1].(Station).name == p.Stops[l-2].(Station).name {
-					continue
+				paths := []Path{}
+				for _, p := range ps {
+					paths = append(paths, p.Path)
 				}
-				paths = append(paths, p)
+				return paths, nil
+			})
+			if err != nil {
+				return nil, err
 			}
+			return paretoFront(paths), nil
+		default:
+			return nil, ErrorUnknownOptimization
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	if len(paths) == 0 {
-		return nil, ErrorPathNotFound
+	for i := range routes {
+		routes[i].Periods = c.appliedPeriods(routes[i].Path, t.Add(time.Duration(walkTo)*time.Minute))
+		routes[i].Warnings = append(c.calendar.coverageWarnings(t), c.lastTrainWarnings(routes[i].Path, t.Add(time.Duration(walkTo)*time.Minute))...)
 	}
+	return withWalking(routes, walkTo, walkFrom, true), nil
+}
 
-	sort.Slice(paths, func(i, j int) bool { return paths[i].Weight < paths[j].Weight })
+// nearestToCoordinates is a helper function which replaces the source and destination by
+// the names of the nearest Stations of the cached Graph within walking distance when
+// navigating from or to Coordinates, and returns the minutes walking to and from them
+func (c cachedGraph) nearestToCoordinates(srcStr, destStr string, opts NavigateOptions) (string, string, Weight, Weight, error) {
+	var walkTo, walkFrom Weight
+	if opts.SourceCoordinates != nil {
+		nearest := nearbyStations(c.stations, c.coordinates, *opts.SourceCoordinates, 1)
+		if len(nearest) == 0 || nearest[0].Meters > maxWalkingMeters {
+			return "", "", 0, 0, ErrorSourceNotFound
+		}
+		srcStr, walkTo = nearest[0].Name, nearest[0].Walking
+	}
+	if opts.DestinationCoordinates != nil {
+		nearest := nearbyStations(c.stations, c.coordinates, *opts.DestinationCoordinates, 1)
+		if len(nearest) == 0 || nearest[0].Meters > maxWalkingMeters {
+			return "", "", 0, 0, ErrorDestinationNotFound
+		}
+		destStr, walkFrom = nearest[0].Name, nearest[0].Walking
+	}
+	return srcStr, destStr, walkTo, walkFrom, nil
+}
 
-	if all {
-		return paths, nil
+// withWalking is a helper function which sets the minutes walking to the source and from
+// the destination of the routes, and adds them to the weight of routes in minutes
+func withWalking(routes []Route, walkTo, walkFrom Weight, inMinutes bool) []Route {
+	for i := range routes {
+		routes[i].WalkToSource, routes[i].WalkFromDestination = walkTo, walkFrom
+		if inMinutes {
+			routes[i].Weight += walkTo + walkFrom
+		}
 	}
-	return paths[:1], nil
+	return routes
 }
 
-// NavigateByTime returns fastest paths between two Stations or any error encountered, knowing the
-// time of travel.
-// It accepts source and destination input as string, which can be either StationID like "DT1"
-// or station name like "Bukit Panjang". If all is set to true, all paths ordered by
-// estimated time are returned instead just the fastest.
-func (n *Navigator) NavigateByTime(srcStr, destStr string, t time.Time, all bool) ([]Path, error) {
-	// get opening stations at the time of travel
-	openingStations := []Station{}
-	for _, station := range n.allStations {
-		// remove if travel before the station exists
-		if t.Before(station.openingDate) {
-			continue
+// navigateVia is a helper function which runs the search from source to destination, or
+// chains the best path of each leg between consecutive points when passing through the via
+// stations. Each leg is searched from the StationID where the previous one ends, knowing
+// the weight accumulated so far.
+func navigateVia(c cachedGraph, srcStr, destStr string, opts NavigateOptions, search func(srcStr, destStr string, opts NavigateOptions, at Weight) ([]Path, error)) ([]Route, error) {
+	if len(opts.Via) == 0 {
+		paths, err := search(srcStr, destStr, opts, 0)
+		if err != nil {
+			return nil, err
 		}
-		// DT, CG and CE lines do not operate at night
-		if isNightHours(t) && stopAtNight(station.id.line) {
-			continue
+		routes := []Route{}
+		for _, p := range paths {
+			routes = append(routes, Route{Path: p})
 		}
-		openingStations = append(openingStations, station)
+		return routes, nil
 	}
 
-	allSrc, srcIsID, err := searchStations(openingStations, srcStr)
+	if opts.Optimize == OptimizePareto {
+		return nil, ErrorViaPareto
+	}
+	for _, via := range opts.Via {
+		if _, _, err := searchStations(c.stations, c.aliases, via); err != nil {
+			return nil, ErrorViaNotFound
+		}
+	}
+
+	legOpts := opts
+	legOpts.Limit = 1
+	legOpts.Via = nil
+
+	route := Route{Path: Path{Stops: []Vertex{}}}
+	from := srcStr
+	for i, to := range append(append([]string{}, opts.Via...), destStr) {
+		paths, err := search(from, to, legOpts, route.Weight)
+		if err == ErrorDestinationAvoided && i < len(opts.Via) {
+			return nil, ErrorViaAvoided
+		}
+		if err != nil {
+			return nil, err
+		}
+		leg := paths[0]
+
+		// the leg starts where the previous one ends
+		stops := leg.Stops
+		if i > 0 {
+			stops = stops[1:]
+		}
+		route.Stops = append(route.Stops, stops...)
+		route.Weight += leg.Weight
+		if i < len(opts.Via) {
+			route.Via = append(route.Via, len(route.Stops)-1)
+		}
+		from = leg.Stops[len(leg.Stops)-1].(Station).id.String()
+	}
+
+	return []Route{route}, nil
+}
+
+// navigate is a helper function which resolves the source and destination among the
+// Stations of the cached Graph, runs the search between each pair of them, and returns
+// the acceptable paths found ordered by weight. The avoided stations and lines are
+// removed from the Graph before searching.
+func navigate(c cachedGraph, srcStr, destStr string, opts NavigateOptions, search func(c cachedGraph, src, dest StationID, accept PathFilter) ([]Path, error)) ([]Path, error) {
+	allSrc, srcIsID, err := searchStations(c.stations, c.aliases, srcStr)
 	if err != nil {
 		return nil, ErrorSourceNotFound
 	}
-	allDest, destIsID, err := searchStations(openingStations, destStr)
+	allDest, destIsID, err := searchStations(c.stations, c.aliases, destStr)
 	if err != nil {
 		return nil, ErrorDestinationNotFound
 	}
+	accept := routeFilter(srcIsID, destIsID)
+
+	if len(opts.AvoidStations) == 0 && len(opts.AvoidLines) == 0 {
+		return searchAll(c, allSrc, allDest, accept, search)
+	}
+
+	avoided, err := c.avoided(opts)
+	if err != nil {
+		return nil, err
+	}
+	avoidedSrc, avoidedDest := withoutAvoided(allSrc, avoided), withoutAvoided(allDest, avoided)
+	if len(avoidedSrc) == 0 {
+		return nil, ErrorSourceAvoided
+	}
+	if len(avoidedDest) == 0 {
+		return nil, ErrorDestinationAvoided
+	}
+	paths, err := searchAll(c.avoiding(avoided), avoidedSrc, avoidedDest, accept, search)
+	if err == ErrorPathNotFound {
+		// tell if it is the avoidance that disconnects source from destination
+		if _, err := searchAll(c, allSrc, allDest, accept, search); err == nil {
+			return nil, ErrorAvoidDisconnected
+		}
+	}
+	return paths, err
+}
+
+// checkAlgorithm is a helper function which tells if the algorithm is supported, knowing
+// if the search is time-dependent
+func checkAlgorithm(algorithm Algorithm, timeDependent bool) error {
+	switch algorithm {
+	case "", AlgorithmDijkstra, AlgorithmAStar:
+		return nil
+	case AlgorithmBidirectional:
+		if timeDependent {
+			return ErrorUnsupportedAlgorithm
+		}
+		return nil
+	default:
+		return ErrorUnknownAlgorithm
+	}
+}
 
-	g := buildGraph(openingStations, getTravelCostByTime(t))
+// search is a helper function which searches up to opts.Limit paths between two Stations
+// of the cached Graph with Yen's algorithm, or the best path with opts.Algorithm when the
+// limit is less than 2. Searches not time-dependent give 1 to every edge.
+func (c cachedGraph) search(src, dest StationID, opts NavigateOptions, weight EdgeWeightFunc, timeDependent bool, accept PathFilter) ([]Path, error) {
+	if opts.Limit > 1 || opts.Algorithm == "" || opts.Algorithm == AlgorithmDijkstra {
+		if !timeDependent {
+			return c.graph.UnweightedSearch(src, dest, opts.Limit, accept)
+		}
+		return c.graph.WeightedSearch(src, dest, opts.Limit, weight, accept)
+	}
 
+	var p Path
+	var err error
+	switch opts.Algorithm {
+	case AlgorithmBidirectional:
+		p, err = c.graph.bidirectionalDijkstra(src, dest, weight)
+	case AlgorithmAStar:
+		p, err = c.graph.TimeDependentAStar(src, dest, weight, c.heuristic())
+	default:
+		err = ErrorUnknownAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Path{p}, nil
+}
+
+// searchAll is a helper function which runs the search between each pair of source
+// and destination, and returns the acceptable paths found ordered by weight
+func searchAll(c cachedGraph, allSrc, allDest []StationID, accept PathFilter, search func(c cachedGraph, src, dest StationID, accept PathFilter) ([]Path, error)) ([]Path, error) {
 	paths := []Path{}
 
 	for _, src := range allSrc {
 		for _, dest := range allDest {
-			ps, err := g.WeightedSearch(src, dest, all)
+			ps, err := search(c, src, dest, accept)
 			if err != nil {
 				continue
 			}
 			for _, p := range ps {
-				l := len(p.Stops)
-				if l < 2 {
-					continue
-				}
-				// filter out paths that start interchanging when source is not pinned to an ID
-				if !srcIsID && p.Stops[0].(Station).name == p.Stops[1].(Station).name {
-					continue
+				if accept(p) {
+					paths = append(paths, p)
 				}
-				// filter out paths that end interchanging when destination is not pinned to an ID
-				if !destIsID && p.Stops[l-1].(Station).name == p.Stops[l-2].(Station).name {
-					continue
-				}
-				paths = append(paths, p)
 			}
 		}
 	}
@@ -132,35 +418,184 @@ func (n *Navigator) NavigateByTime(srcStr, destStr string, t time.Time, all bool
 		return nil, ErrorPathNotFound
 	}
 
-	sort.Slice(paths, func(i, j int) bool { return paths[i].Weight < paths[j].Weight })
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].Weight < paths[j].Weight })
+	return paths, nil
+}
+
+// limitPaths is a helper function which keeps at most limit paths, and at least one
+func limitPaths(paths []Path, limit int) []Path {
+	if limit < 1 {
+		limit = 1
+	}
+	if len(paths) > limit {
+		return paths[:limit]
+	}
+	return paths
+}
+
+// routeMetrics counts the interchanges and the stops travelled on lines of a path
+func routeMetrics(p Path) (interchanges int, stops int) {
+	for i := 1; i < len(p.Stops); i++ {
+		if p.Stops[i-1].(Station).id.line == p.Stops[i].(Station).id.line {
+			stops++
+		} else {
+			interchanges++
+		}
+	}
+	return interchanges, stops
+}
+
+// paretoFront is a helper function which keeps the paths ordered by weight that are not
+// dominated by another in weight, interchanges and stops, nor equal to an earlier one
+func paretoFront(paths []Path) []Path {
+	criteria := make([]Criteria, len(paths))
+	for i, p := range paths {
+		interchanges, stops := routeMetrics(p)
+		criteria[i] = Criteria{p.Weight, Weight(interchanges), Weight(stops)}
+	}
+	front := []Path{}
+	for i, p := range paths {
+		dominated := false
+		for j := range paths {
+			if i != j && criteria[j].dominates(criteria[i]) && (criteria[j].less(criteria[i]) || j < i) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, p)
+		}
+	}
+	return front
+}
+
+// routeFilter returns a PathFilter rejecting paths which
+// 1) start interchanging when source is not pinned to an ID;
+// 2) end interchanging when destination is not pinned to an ID;
+// 3) come back to the same station right after leaving, eg. interchanging twice in a row.
+func routeFilter(srcIsID, destIsID bool) PathFilter {
+	return func(p Path) bool {
+		l := len(p.Stops)
+		if l < 2 {
+			return false
+		}
+		if !srcIsID && p.Stops[0].(Station).name == p.Stops[1].(Station).name {
+			return false
+		}
+		if !destIsID && p.Stops[l-1].(Station).name == p.Stops[l-2].(Station).name {
+			return false
+		}
+		for i := 2; i < l; i++ {
+			if p.Stops[i].(Station).name == p.Stops[i-2].(Station).name {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// timeDependentWeight returns an EdgeWeightFunc for a journey from the source to the
+// destination departing at the given time. Each edge is priced by the TravelCost at the
+// moment it is reached, including the wait for boarding the train where the rider boards:
+// on the first edge from the source unless the journey continues on board from a previous
+// leg, and on each interchange but one into the destination, where no train follows.
+// Edges of lines not operating are dropped once the journey runs into a period they are
+// closed in. Boardings outside the first and last train times are dropped too, while a
+// rider on board rides on after the last train has left; after an interchange the line
+// must be in service toward either direction, as the next edge is not known yet.
+func (c cachedGraph) timeDependentWeight(departure time.Time, src, dest VertexID, onBoard bool) EdgeWeightFunc {
+	return func(u, v VertexID, _ Weight, at Weight) (Weight, bool) {
+		t := departure.Add(time.Duration(at) * time.Minute)
+		from, to := u.(StationID), v.(StationID)
+		period := c.schedule.travelPeriod(t, c.calendar)
+		if c.schedule.isClosed(period, from.line) || c.schedule.isClosed(period, to.line) {
+			return 0, false
+		}
+		cost := c.travelCosts[period]
+		if from.line == to.line {
+			if u != src || onBoard {
+				return c.onLine(cost, from, to), true
+			}
+			if h, ok := c.services.hoursOf(from, to); ok && !h.inService(t) {
+				return 0, false
+			}
+			return cost.Boarding(from) + c.onLine(cost, from, to), true
+		}
+		walk := cost.Interchange(from, to)
+		if v == dest {
+			return walk, true
+		}
+		if !c.inServiceFrom(to, t.Add(time.Duration(walk)*time.Minute)) {
+			return 0, false
+		}
+		return walk + cost.Boarding(to), true
+	}
+}
+
+// pathWeight is a helper function which returns the timeDependentWeight of a journey
+// along the Path departing at the given time
+func (c cachedGraph) pathWeight(p Path, departure time.Time) EdgeWeightFunc {
+	return c.timeDependentWeight(departure, p.Stops[0].ID(), p.Stops[len(p.Stops)-1].ID(), false)
+}
 
-	if all {
-		return paths, nil
+// appliedPeriods is a helper function to list the distinct travel periods in order, whose
+// TravelCosts price the edges of the Path departing at the given time
+func (c cachedGraph) appliedPeriods(p Path, departure time.Time) []string {
+	weight := c.pathWeight(p, departure)
+	periods := []string{c.schedule.travelPeriod(departure, c.calendar)}
+	var at Weight
+	for i := 1; i < len(p.Stops); i++ {
+		if period := c.schedule.travelPeriod(departure.Add(time.Duration(at)*time.Minute), c.calendar); period != periods[len(periods)-1] {
+			periods = append(periods, period)
+		}
+		w, _ := weight(p.Stops[i-1].ID(), p.Stops[i].ID(), 0, at)
+		at += w
+	}
+	return periods
+}
+
+// timeDependentCriteria returns a CriteriaFunc of minutes, interchanges and stops for a
+// journey from the source to the destination departing at the given time, where minutes
+// are evaluated like timeDependentWeight
+func (c cachedGraph) timeDependentCriteria(departure time.Time, src, dest VertexID, onBoard bool) CriteriaFunc {
+	weight := c.timeDependentWeight(departure, src, dest, onBoard)
+	return func(u, v VertexID, w Weight, at Criteria) (Criteria, bool) {
+		minutes, ok := weight(u, v, w, at[0])
+		if !ok {
+			return nil, false
+		}
+		if u.(StationID).line == v.(StationID).line {
+			return Criteria{minutes, 0, 1}, true
+		}
+		return Criteria{minutes, 1, 0}, true
 	}
-	return paths[:1], nil
 }
 
 // buildGraph takes a list of Stations and connects them in a Graph:
 //
-// 1) each Station on the same MRT line is connected to its adjacent Stations,
-// eg. EW1 <-> EW2 <-> EW4 (assuming EW3 not exists)
+// 1) each Station on the same MRT line is connected to its adjacent Stations as
+// given by the line Segments. Stations referenced by Segments but missing from the
+// list (eg. not opened yet) are passed through,
+// eg. EW1 <-> EW2 <-> EW4 (assuming EW3 not exists on segments EW2-EW3 and EW3-EW4)
 //
 // 2) Stations with the same name but different StationIDs will be treated as
 // interchange stations, so they will be connected to each other
 // eg. NS24 Dhoby Ghaut <-> CC1 Dhoby Ghaut <-> NE6 Dhoby Ghaut (<-> NS24 Dhoby Ghaut)
-func buildGraph(stations []Station, cost TravelCost) *Graph {
+func buildGraph(stations []Station, segments []Segment, cost TravelCost) *Graph {
 	g := NewGraph()
 
 	// add all Stations as graph vertices
+	present := make(map[StationID]Station)
 	for _, s := range stations {
 		g.Add(s)
+		present[s.id] = s
 	}
 
-	// link adajent Stations on the same line
-	for line, ss := range groupBy(stations, func(s Station) string { return s.id.line }) {
-		sort.Slice(ss, func(i, j int) bool { return ss[i].id.number < ss[j].id.number })
-		for i := 1; i < len(ss); i++ {
-			g.LinkBoth(ss[i-1], ss[i], cost.OnLine(line))
+	// link adjacent Stations along the line segments, priced by each segment passed
+	hops := lineHops(stations, segments)
+	for _, s := range stations {
+		for _, hop := range hops[s.id] {
+			g.LinkBoth(s, present[hop[len(hop)-1]], rideCost(cost, hop))
 		}
 	}
 
@@ -168,7 +603,7 @@ func buildGraph(stations []Station, cost TravelCost) *Graph {
 	for _, ss := range groupBy(stations, func(s Station) string { return s.name }) {
 		for i := 0; i < len(ss); i++ {
 			for j := i + 1; j < len(ss); j++ {
-				g.LinkBoth(ss[i], ss[j], cost.Interchange())
+				g.LinkBoth(ss[i], ss[j], cost.Interchange(ss[i].id, ss[j].id))
 			}
 		}
 	}
@@ -176,6 +611,63 @@ func buildGraph(stations []Station, cost TravelCost) *Graph {
 	return g
 }
 
+// lineHops is a helper function to find the rides from each Station to its adjacent
+// Stations on the line, by the StationIDs along the Segments from one to the other,
+// eg. [EW2 EW3 EW4] from EW2 to EW4 passing through EW3 not in the list
+func lineHops(stations []Station, segments []Segment) map[StationID][][]StationID {
+	present := make(map[StationID]bool)
+	for _, s := range stations {
+		present[s.id] = true
+	}
+	adjacent := make(map[StationID][]StationID)
+	for _, seg := range segments {
+		adjacent[seg.from] = append(adjacent[seg.from], seg.to)
+		adjacent[seg.to] = append(adjacent[seg.to], seg.from)
+	}
+	hops := make(map[StationID][][]StationID)
+	for _, s := range stations {
+		hops[s.id] = nextPresent(s.id, adjacent, present)
+	}
+	return hops
+}
+
+// nextPresent is a helper function to find the nearest present Stations reachable from
+// the given one along the segments, passing through Stations that are not present, and
+// returns the StationIDs along the way to each of them
+func nextPresent(from StationID, adjacent map[StationID][]StationID, present map[StationID]bool) [][]StationID {
+	result := [][]StationID{}
+	visited := map[StationID]bool{from: true}
+	stack := [][]StationID{{from}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, neighbor := range adjacent[current[len(current)-1]] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			// copy to keep the branches of the way apart
+			next := append(append([]StationID{}, current...), neighbor)
+			if present[neighbor] {
+				result = append(result, next)
+			} else {
+				stack = append(stack, next)
+			}
+		}
+	}
+	return result
+}
+
+// rideCost is a helper function to price the ride along the StationIDs by adding up the
+// TravelCost of each Segment of it
+func rideCost(cost TravelCost, hop []StationID) Weight {
+	var total Weight
+	for i := 1; i < len(hop); i++ {
+		total += cost.OnLine(hop[i-1], hop[i])
+	}
+	return total
+}
+
 // groupBy is a helper function to group Stations by key func
 func groupBy(stations []Station, key func(Station) string) map[string][]Station {
 	m := make(map[string][]Station)
@@ -6,6 +6,26 @@ import (
 	"time"
 )
 
+// defaultNetwork is a helper function to load the Network embedded from ./data, which
+// panics on error
+func defaultNetwork() Network {
+	network, err := defaultSource().Network()
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// defaultNavigator is a helper function to create the Navigator of the Network embedded
+// from ./data, which panics on error
+func defaultNavigator() *Navigator {
+	n, err := NewNavigator()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
 func TestBuildGraph(t *testing.T) {
 	travelCost := TravelCostByTime{
 		interchange: 1,
@@ -36,7 +56,11 @@ func TestBuildGraph(t *testing.T) {
 			name: "Bras Basah",
 		},
 	}
-	g := buildGraph(stations, travelCost)
+	segments := []Segment{
+		Segment{from: StationID{line: "NE", number: 5}, to: StationID{line: "NE", number: 6}},
+		Segment{from: StationID{line: "CC", number: 1}, to: StationID{line: "CC", number: 2}},
+	}
+	g := buildGraph(stations, segments, travelCost)
 
 	// need to use the type same as Graph.Edges
 	expectedEdges := map[VertexID]map[VertexID]Weight{
@@ -60,6 +84,39 @@ func TestBuildGraph(t *testing.T) {
 	}
 }
 
+func TestBuildGraphPassThrough(t *testing.T) {
+	// CG0 <-> CG1 <-> CG2, where CG1 is not in the station list
+	stations := []Station{
+		Station{
+			id:   StationID{line: "CG", number: 0},
+			name: "Tanah Merah",
+		},
+		Station{
+			id:   StationID{line: "CG", number: 2},
+			name: "Changi Airport",
+		},
+	}
+	segments := []Segment{
+		Segment{from: StationID{line: "CG", number: 0}, to: StationID{line: "CG", number: 1}},
+		Segment{from: StationID{line: "CG", number: 1}, to: StationID{line: "CG", number: 2}},
+	}
+	// the ride passing through CG1 is priced by both segments
+	segmentTimes := []SegmentTime{{segment: segments[0], minutes: 3}}
+	g := buildGraph(stations, segments, newTravelCostBySegment(segmentTimes, nil, periodPeak, TravelCostByStop{}))
+
+	expectedEdges := map[VertexID]map[VertexID]Weight{
+		StationID{line: "CG", number: 0}: map[VertexID]Weight{
+			StationID{line: "CG", number: 2}: 4,
+		},
+		StationID{line: "CG", number: 2}: map[VertexID]Weight{
+			StationID{line: "CG", number: 0}: 4,
+		},
+	}
+	if !reflect.DeepEqual(g.Edges, expectedEdges) {
+		t.Errorf("Edges not match\nexpected: %v\n  actual: %v", expectedEdges, g.Edges)
+	}
+}
+
 type ExpectedPath struct {
 	path   []string
 	weight Weight
@@ -69,7 +126,7 @@ func TestNavigateByStops(t *testing.T) {
 	for _, testCase := range []struct {
 		src         string
 		dest        string
-		all         bool
+		limit       int
 		expectError bool
 		expected    []ExpectedPath
 	}{
@@ -94,32 +151,32 @@ func TestNavigateByStops(t *testing.T) {
 			expectError: true,
 		},
 		{
-			src:  "NE4",
-			dest: "DT19",
-			all:  false,
+			src:   "NE4",
+			dest:  "DT19",
+			limit: 1,
 			expected: []ExpectedPath{
 				ExpectedPath{weight: 1, path: []string{"NE4", "DT19"}},
 			},
 		},
 		{
-			src:  "CC21",
-			dest: "DT14",
-			all:  false,
+			src:   "CC21",
+			dest:  "DT14",
+			limit: 1,
 			expected: []ExpectedPath{
 				ExpectedPath{weight: 8, path: []string{"CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14"}},
 			},
 		},
 		{
-			src:  "Jurong East",
-			dest: "HarbourFront",
-			all:  true,
+			src:   "Jurong East",
+			dest:  "HarbourFront",
+			limit: 2,
 			expected: []ExpectedPath{
 				ExpectedPath{weight: 10, path: []string{"EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "NE3", "NE1"}},
 				ExpectedPath{weight: 11, path: []string{"EW24", "EW23", "EW22", "EW21", "CC22", "CC23", "CC24", "CC25", "CC26", "CC27", "CC28", "CC29"}},
 			},
 		},
 	} {
-		paths, err := NewNavigator().NavigateByStops(testCase.src, testCase.dest, testCase.all)
+		paths, err := defaultNavigator().NavigateByStops(testCase.src, testCase.dest, NavigateOptions{Limit: testCase.limit})
 		if testCase.expectError {
 			if err == nil {
 				t.Errorf("expect error '%s' to '%s'", testCase.src, testCase.dest)
@@ -145,7 +202,7 @@ func TestNavigateByTime(t *testing.T) {
 		src         string
 		dest        string
 		timeStr     string
-		all         bool
+		limit       int
 		expectError bool
 		expected    []ExpectedPath
 	}{
@@ -177,54 +234,111 @@ func TestNavigateByTime(t *testing.T) {
 			src:     "EW27",
 			dest:    "DT12",
 			timeStr: peakHours,
-			all:     false,
+			limit:   1,
 			expected: []ExpectedPath{
-				ExpectedPath{weight: 150, path: []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"}},
+				ExpectedPath{weight: 156, path: []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"}},
 			},
 		},
 		{
+			// ends on the interchange to CC4 without waiting for a CC line train
 			src:     "CC19",
 			dest:    "CC4",
 			timeStr: nonPeakHours,
-			all:     false,
+			limit:   1,
 			expected: []ExpectedPath{
-				ExpectedPath{weight: 68, path: []string{"CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14", "DT15", "CC4"}},
+				ExpectedPath{weight: 71, path: []string{"CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14", "DT15", "CC4"}},
 			},
 		},
 		{
 			src:     "CC19",
 			dest:    "CC4",
 			timeStr: nightHours,
-			all:     false,
+			limit:   1,
+			expected: []ExpectedPath{
+				ExpectedPath{weight: 89, path: []string{"CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14", "DT15", "CC4"}},
+			},
+		},
+		{
+			// DT line is reached after the interchange before its first train at 05:50
+			src:     "CC19",
+			dest:    "CC4",
+			timeStr: "2020-11-09T05:30",
+			limit:   1,
+			expected: []ExpectedPath{
+				ExpectedPath{weight: 144, path: []string{"CC19", "CC17", "CC16", "CC15", "CC14", "CC13", "CC12", "CC11", "CC10", "CC9", "CC8", "CC7", "CC6", "CC5", "CC4"}},
+			},
+		},
+		{
+			// boards before the last EW line train at 00:30 and rides on after it
+			src:     "Jurong East",
+			dest:    "Tanah Merah",
+			timeStr: "2020-11-10T00:20",
+			limit:   1,
 			expected: []ExpectedPath{
-				ExpectedPath{weight: 140, path: []string{"CC19", "CC17", "CC16", "CC15", "CC14", "CC13", "CC12", "CC11", "CC10", "CC9", "CC8", "CC7", "CC6", "CC5", "CC4"}},
+				ExpectedPath{weight: 204, path: []string{"EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "EW15", "EW14", "EW13", "EW12", "EW11", "EW10", "EW9", "EW8", "EW7", "EW6", "EW5", "EW4"}},
+			},
+		},
+		{
+			// no train to board after the last one
+			src:         "Jurong East",
+			dest:        "Tanah Merah",
+			timeStr:     "2020-11-10T00:35",
+			expectError: true,
+		},
+		{
+			// departs at non-peak hours, but runs into peak hours from 18:00
+			src:     "NS1",
+			dest:    "NS28",
+			timeStr: "2020-11-09T17:00",
+			limit:   1,
+			expected: []ExpectedPath{
+				ExpectedPath{weight: 128, path: []string{"NS1", "EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "EW15", "EW14", "NS26", "NS27", "NS28"}},
+			},
+		},
+		{
+			// DT line keeps operating after 22:00 until its last train
+			src:     "CC19",
+			dest:    "CC4",
+			timeStr: "2020-11-09T21:55",
+			limit:   1,
+			expected: []ExpectedPath{
+				ExpectedPath{weight: 83, path: []string{"CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14", "DT15", "CC4"}},
 			},
 		},
 		{
 			src:     "Boon Lay",
 			dest:    "Little India",
 			timeStr: peakHours,
-			all:     false,
+			limit:   1,
+			expected: []ExpectedPath{
+				ExpectedPath{weight: 156, path: []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"}},
+			},
+		},
+		{
+			src:     "NS2",
+			dest:    "EW23",
+			timeStr: peakHours,
+			limit:   1,
 			expected: []ExpectedPath{
-				ExpectedPath{weight: 150, path: []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"}},
+				ExpectedPath{weight: 29, path: []string{"NS2", "NS1", "EW24", "EW23"}},
 			},
 		},
 		{
 			src:     "Jurong East",
 			dest:    "HarbourFront",
 			timeStr: peakHours,
-			all:     true,
+			limit:   2,
 			expected: []ExpectedPath{
-				ExpectedPath{weight: 107, path: []string{"EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "NE3", "NE1"}},
-				ExpectedPath{weight: 115, path: []string{"EW24", "EW23", "EW22", "EW21", "CC22", "CC23", "CC24", "CC25", "CC26", "CC27", "CC28", "CC29"}},
+				ExpectedPath{weight: 111, path: []string{"EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "NE3", "NE1"}},
+				ExpectedPath{weight: 119, path: []string{"EW24", "EW23", "EW22", "EW21", "CC22", "CC23", "CC24", "CC25", "CC26", "CC27", "CC28", "CC29"}},
 			},
 		},
 	} {
-		travelTime, err := time.Parse("2006-01-02T15:04", testCase.timeStr)
+		travelTime, err := time.ParseInLocation("2006-01-02T15:04", testCase.timeStr, networkLocation)
 		if err != nil {
 			t.Error(err)
 		}
-		paths, err := NewNavigator().NavigateByTime(testCase.src, testCase.dest, travelTime, testCase.all)
+		paths, err := defaultNavigator().NavigateByTime(testCase.src, testCase.dest, travelTime, NavigateOptions{Limit: testCase.limit})
 		if testCase.expectError {
 			if err == nil {
 				t.Errorf("expect error '%s' to '%s'", testCase.src, testCase.dest)
@@ -241,6 +355,144 @@ func TestNavigateByTime(t *testing.T) {
 	}
 }
 
+func TestNavigateByTimePareto(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	expected := []struct {
+		path         []string
+		weight       Weight
+		interchanges int
+		stops        int
+	}{
+		{
+			path:         []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12"},
+			weight:       143,
+			interchanges: 2,
+			stops:        12,
+		},
+		{
+			path:         []string{"EW27", "EW26", "EW25", "EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "NE3", "NE4", "NE5", "NE6", "NE7"},
+			weight:       166,
+			interchanges: 1,
+			stops:        15,
+		},
+	}
+
+	paths, err := defaultNavigator().NavigateByTime("Boon Lay", "Little India", travelTime, NavigateOptions{Optimize: OptimizePareto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %d paths, actual: %v", len(expected), paths)
+	}
+	for i, p := range paths {
+		interchanges, stops := routeMetrics(p.Path)
+		if !reflect.DeepEqual(pathToStringSlice(p.Stops), expected[i].path) || p.Weight != expected[i].weight ||
+			interchanges != expected[i].interchanges || stops != expected[i].stops {
+			t.Errorf("\nexpected: %v, \n  actual: %v %d %d %d", expected[i], pathToStringSlice(p.Stops), p.Weight, interchanges, stops)
+		}
+	}
+
+	_, err = defaultNavigator().NavigateByTime("Boon Lay", "Little India", travelTime, NavigateOptions{Optimize: "scenic"})
+	if err != ErrorUnknownOptimization {
+		t.Errorf("expected: %v, actual: %v", ErrorUnknownOptimization, err)
+	}
+}
+
+func TestNavigateAvoid(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	jurongEastToHarbourFrontByCC := []string{"EW24", "EW23", "EW22", "EW21", "CC22", "CC23", "CC24", "CC25", "CC26", "CC27", "CC28", "CC29"}
+
+	for _, testCase := range []struct {
+		name          string
+		src           string
+		dest          string
+		avoidStations []string
+		avoidLines    []string
+		expectedError error
+		expected      []string
+	}{
+		{
+			name:       "avoid line",
+			src:        "Jurong East",
+			dest:       "HarbourFront",
+			avoidLines: []string{"ne"},
+			expected:   jurongEastToHarbourFrontByCC,
+		},
+		{
+			name:          "avoid station by name",
+			src:           "Jurong East",
+			dest:          "HarbourFront",
+			avoidStations: []string{"Outram Park"},
+			expected:      jurongEastToHarbourFrontByCC,
+		},
+		{
+			name:          "avoid station by id",
+			src:           "Jurong East",
+			dest:          "HarbourFront",
+			avoidStations: []string{"NE3"},
+			expected:      jurongEastToHarbourFrontByCC,
+		},
+		{
+			name:          "avoid unknown station",
+			src:           "Jurong East",
+			dest:          "HarbourFront",
+			avoidStations: []string{"???"},
+			expectedError: ErrorAvoidNotFound,
+		},
+		{
+			name:          "avoid unknown line",
+			src:           "Jurong East",
+			dest:          "HarbourFront",
+			avoidLines:    []string{"XX"},
+			expectedError: ErrorAvoidNotFound,
+		},
+		{
+			name:          "avoid source",
+			src:           "Jurong East",
+			dest:          "HarbourFront",
+			avoidLines:    []string{"EW", "NS"},
+			expectedError: ErrorSourceAvoided,
+		},
+		{
+			name:          "avoid destination",
+			src:           "Jurong East",
+			dest:          "Changi Airport",
+			avoidLines:    []string{"CG"},
+			expectedError: ErrorDestinationAvoided,
+		},
+		{
+			name:          "avoid disconnecting",
+			src:           "Tanah Merah",
+			dest:          "Changi Airport",
+			avoidStations: []string{"Expo"},
+			expectedError: ErrorAvoidDisconnected,
+		},
+	} {
+		opts := NavigateOptions{AvoidStations: testCase.avoidStations, AvoidLines: testCase.avoidLines}
+		byStops, errByStops := defaultNavigator().NavigateByStops(testCase.src, testCase.dest, opts)
+		byTime, errByTime := defaultNavigator().NavigateByTime(testCase.src, testCase.dest, travelTime, opts)
+		if errByStops != testCase.expectedError || errByTime != testCase.expectedError {
+			t.Errorf("%s expected error: %v, actual: %v, %v", testCase.name, testCase.expectedError, errByStops, errByTime)
+			continue
+		}
+		if testCase.expectedError != nil {
+			continue
+		}
+		if actual := pathToStringSlice(byStops[0].Stops); !reflect.DeepEqual(testCase.expected, actual) {
+			t.Errorf("%s by stops\nexpected: %v, \n  actual: %v", testCase.name, testCase.expected, actual)
+		}
+		if actual := pathToStringSlice(byTime[0].Stops); !reflect.DeepEqual(testCase.expected, actual) {
+			t.Errorf("%s by time\nexpected: %v, \n  actual: %v", testCase.name, testCase.expected, actual)
+		}
+		// A* keeps the landmarks of the Graph without avoided Stations
+		opts.Algorithm = AlgorithmAStar
+		byAStar, err := defaultNavigator().NavigateByTime(testCase.src, testCase.dest, travelTime, opts)
+		if err != nil || byAStar[0].Weight != byTime[0].Weight {
+			t.Errorf("%s by A* expected weight: %d, actual: %v, %v", testCase.name, byTime[0].Weight, byAStar, err)
+		}
+	}
+}
+
 // pathToStringSlice is a helper function convert Path to station codes in string
 func pathToStringSlice(path []Vertex) []string {
 	actual := []string{}
@@ -250,41 +502,291 @@ func pathToStringSlice(path []Vertex) []string {
 	return actual
 }
 
+func TestNavigateVia(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	jurongEastToChangiAirportViaOrchard := []string{"EW24", "EW23", "EW22", "EW21", "CC22", "CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "NS21", "NS22", "NS23", "NS24", "NS25", "EW13", "EW12", "EW11", "EW10", "EW9", "EW8", "EW7", "EW6", "EW5", "EW4", "CG0", "CG1", "CG2"}
+
+	for _, testCase := range []struct {
+		name          string
+		via           []string
+		optimize      Optimization
+		expectedError error
+		expected      []string
+		expectedVia   []int
+		expectedStops Weight
+		expectedTime  Weight
+	}{
+		{
+			name:          "via station name",
+			via:           []string{"Orchard"},
+			expected:      jurongEastToChangiAirportViaOrchard,
+			expectedVia:   []int{12},
+			expectedStops: 28,
+			expectedTime:  287,
+		},
+		{
+			name:          "via station id",
+			via:           []string{"NS22"},
+			expected:      jurongEastToChangiAirportViaOrchard,
+			expectedVia:   []int{12},
+			expectedStops: 28,
+			expectedTime:  287,
+		},
+		{
+			name:          "via stations in order",
+			via:           []string{"Orchard", "Bugis"},
+			expected:      jurongEastToChangiAirportViaOrchard,
+			expectedVia:   []int{12, 17},
+			expectedStops: 28,
+			expectedTime:  287,
+		},
+		{
+			name:          "via unknown station",
+			via:           []string{"???"},
+			expectedError: ErrorViaNotFound,
+		},
+		{
+			name:          "via with pareto",
+			via:           []string{"Orchard"},
+			optimize:      OptimizePareto,
+			expectedError: ErrorViaPareto,
+		},
+	} {
+		opts := NavigateOptions{Via: testCase.via, Optimize: testCase.optimize}
+		byStops, errByStops := defaultNavigator().NavigateByStops("Jurong East", "Changi Airport", opts)
+		byTime, errByTime := defaultNavigator().NavigateByTime("Jurong East", "Changi Airport", travelTime, opts)
+		if errByTime != testCase.expectedError {
+			t.Errorf("%s expected error: %v, actual: %v", testCase.name, testCase.expectedError, errByTime)
+			continue
+		}
+		if testCase.expectedError != nil {
+			continue
+		}
+		if errByStops != nil {
+			t.Errorf("%s unexpected error: %v", testCase.name, errByStops)
+			continue
+		}
+		for _, routes := range [][]Route{byStops, byTime} {
+			if len(routes) != 1 {
+				t.Errorf("%s expected 1 route, actual: %d", testCase.name, len(routes))
+				continue
+			}
+			if actual := pathToStringSlice(routes[0].Stops); !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("%s expected path: %v, actual: %v", testCase.name, testCase.expected, actual)
+			}
+			if !reflect.DeepEqual(testCase.expectedVia, routes[0].Via) {
+				t.Errorf("%s expected via: %v, actual: %v", testCase.name, testCase.expectedVia, routes[0].Via)
+			}
+		}
+		if byStops[0].Weight != testCase.expectedStops || byTime[0].Weight != testCase.expectedTime {
+			t.Errorf("%s expected weights: %d, %d, actual: %d, %d", testCase.name, testCase.expectedStops, testCase.expectedTime, byStops[0].Weight, byTime[0].Weight)
+		}
+	}
+}
+
+func TestNavigateReachable(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+
+	paths, err := defaultNavigator().Reachable("Bishan", travelTime, 22)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []ExpectedPath{
+		ExpectedPath{weight: 0, path: []string{"NS17"}},
+		ExpectedPath{weight: 0, path: []string{"CC15"}},
+		ExpectedPath{weight: 12, path: []string{"CC15", "CC14"}},
+		ExpectedPath{weight: 12, path: []string{"CC15", "CC16"}},
+		ExpectedPath{weight: 14, path: []string{"NS17", "NS16"}},
+		ExpectedPath{weight: 14, path: []string{"NS17", "NS18"}},
+		ExpectedPath{weight: 22, path: []string{"CC15", "CC14", "CC13"}},
+		ExpectedPath{weight: 22, path: []string{"CC15", "CC16", "CC17"}},
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %d paths, actual: %d", len(expected), len(paths))
+	}
+	for i, p := range paths {
+		if actual := pathToStringSlice(p.Stops); !reflect.DeepEqual(expected[i].path, actual) || p.Weight != expected[i].weight {
+			t.Errorf("expected path: %v (%d), actual: %v (%d)", expected[i].path, expected[i].weight, actual, p.Weight)
+		}
+	}
+
+	if _, err := defaultNavigator().Reachable("???", travelTime, 20); err != ErrorSourceNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorSourceNotFound, err)
+	}
+}
+
+func TestNavigateAlgorithms(t *testing.T) {
+	navigator := defaultNavigator()
+	stations := []string{"Jurong East", "HarbourFront", "Changi Airport", "Bishan", "DT1", "Promenade", "Marina South Pier"}
+	times := []string{"2020-11-09T08:00", "2020-11-09T17:50", "2020-11-09T21:55", "2020-11-14T12:00"}
+
+	for _, src := range stations {
+		for _, dest := range stations {
+			if src == dest {
+				continue
+			}
+			expected, err := navigator.NavigateByStops(src, dest, NavigateOptions{})
+			for _, algorithm := range []Algorithm{AlgorithmBidirectional, AlgorithmAStar} {
+				actual, errActual := navigator.NavigateByStops(src, dest, NavigateOptions{Algorithm: algorithm})
+				if errActual != err || (err == nil && actual[0].Weight != expected[0].Weight) {
+					t.Errorf("%s by stops from %s to %s expected: %v, %v, actual: %v, %v", algorithm, src, dest, expected, err, actual, errActual)
+					continue
+				}
+				// routes of the same weight may differ, so the path is checked to be one
+				if err == nil && !isRouteOf(navigator.graphByStops(), actual[0], expected[0], staticWeight) {
+					t.Errorf("%s by stops from %s to %s invalid path: %v", algorithm, src, dest, pathToStringSlice(actual[0].Stops))
+				}
+			}
+			for _, s := range times {
+				travelTime, _ := time.ParseInLocation("2006-01-02T15:04", s, networkLocation)
+				expected, err := navigator.NavigateByTime(src, dest, travelTime, NavigateOptions{})
+				actual, errActual := navigator.NavigateByTime(src, dest, travelTime, NavigateOptions{Algorithm: AlgorithmAStar})
+				if errActual != err || (err == nil && actual[0].Weight != expected[0].Weight) {
+					t.Errorf("%s by time at %s from %s to %s expected: %v, %v, actual: %v, %v", AlgorithmAStar, s, src, dest, expected, err, actual, errActual)
+					continue
+				}
+				c := navigator.graphAt(travelTime)
+				if err == nil && !isRouteOf(c, actual[0], expected[0], c.pathWeight(actual[0].Path, travelTime)) {
+					t.Errorf("%s by time at %s from %s to %s invalid path: %v", AlgorithmAStar, s, src, dest, pathToStringSlice(actual[0].Stops))
+				}
+			}
+		}
+	}
+
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	if _, err := navigator.NavigateByTime("Bishan", "Orchard", travelTime, NavigateOptions{Algorithm: AlgorithmBidirectional}); err != ErrorUnsupportedAlgorithm {
+		t.Errorf("expected error: %v, actual: %v", ErrorUnsupportedAlgorithm, err)
+	}
+	if _, err := navigator.NavigateByStops("Bishan", "Orchard", NavigateOptions{Algorithm: "???"}); err != ErrorUnknownAlgorithm {
+		t.Errorf("expected error: %v, actual: %v", ErrorUnknownAlgorithm, err)
+	}
+}
+
+func TestNavigateAlgorithmsPath(t *testing.T) {
+	navigator := defaultNavigator()
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T10:00", networkLocation)
+	for _, testCase := range []struct {
+		src      string
+		dest     string
+		expected []string
+	}{
+		{src: "NE4", dest: "DT19", expected: []string{"NE4", "DT19"}},
+		{src: "CC21", dest: "DT14", expected: []string{"CC21", "CC20", "CC19", "DT9", "DT10", "DT11", "DT12", "DT13", "DT14"}},
+		{src: "EW24", dest: "NE1", expected: []string{"EW24", "EW23", "EW22", "EW21", "EW20", "EW19", "EW18", "EW17", "EW16", "NE3", "NE1"}},
+	} {
+		for _, algorithm := range []Algorithm{AlgorithmBidirectional, AlgorithmAStar} {
+			routes, err := navigator.NavigateByStops(testCase.src, testCase.dest, NavigateOptions{Algorithm: algorithm})
+			if err != nil {
+				t.Errorf("%s by stops from %s to %s unexpected error: %s", algorithm, testCase.src, testCase.dest, err)
+			} else if actual := pathToStringSlice(routes[0].Stops); !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("%s by stops from %s to %s\nexpected: %v, \n  actual: %v", algorithm, testCase.src, testCase.dest, testCase.expected, actual)
+			}
+		}
+		routes, err := navigator.NavigateByTime(testCase.src, testCase.dest, travelTime, NavigateOptions{Algorithm: AlgorithmAStar})
+		if err != nil {
+			t.Errorf("%s by time from %s to %s unexpected error: %s", AlgorithmAStar, testCase.src, testCase.dest, err)
+		} else if actual := pathToStringSlice(routes[0].Stops); !reflect.DeepEqual(testCase.expected, actual) {
+			t.Errorf("%s by time from %s to %s\nexpected: %v, \n  actual: %v", AlgorithmAStar, testCase.src, testCase.dest, testCase.expected, actual)
+		}
+	}
+}
+
+// isRouteOf is a helper function to check the actual Route is a path on the cached Graph
+// between the Stations of the same names as the expected Route, weighing as much by the
+// weight function
+func isRouteOf(c cachedGraph, actual, expected Route, weight EdgeWeightFunc) bool {
+	endpoints := func(r Route) [2]string {
+		return [2]string{r.Stops[0].(Station).name, r.Stops[len(r.Stops)-1].(Station).name}
+	}
+	if endpoints(actual) != endpoints(expected) {
+		return false
+	}
+	w, ok := c.graph.Evaluate(actual.Stops, weight)
+	return ok && w == actual.Weight
+}
+
 //// Benchmarks on Navigator methods
 func BenchmarkNavigateByStopsSingle(b *testing.B) {
-	var navigatorForBenchmark = NewNavigator()
+	var navigatorForBenchmark = defaultNavigator()
 	var source, destination = "Botanic Garden", "Promenade"
 
 	for i := 0; i < b.N; i++ {
-		navigatorForBenchmark.NavigateByStops(source, destination, false)
+		navigatorForBenchmark.NavigateByStops(source, destination, NavigateOptions{Limit: 1})
 	}
 }
 
 func BenchmarkNavigateByStopsAll(b *testing.B) {
-	var navigatorForBenchmark = NewNavigator()
+	var navigatorForBenchmark = defaultNavigator()
 	var source, destination = "Botanic Garden", "Promenade"
 
 	for i := 0; i < b.N; i++ {
-		navigatorForBenchmark.NavigateByStops(source, destination, true)
+		navigatorForBenchmark.NavigateByStops(source, destination, NavigateOptions{Limit: 3})
 	}
 }
 
 func BenchmarkNavigateByTimeSingle(b *testing.B) {
-	var navigatorForBenchmark = NewNavigator()
+	var navigatorForBenchmark = defaultNavigator()
 	var source, destination = "Botanic Garden", "Promenade"
-	var travelTime, _ = time.Parse("2006-01-02T15:04", "2020-11-09T06:01")
+	var travelTime, _ = time.ParseInLocation("2006-01-02T15:04", "2020-11-09T06:01", networkLocation)
 
 	for i := 0; i < b.N; i++ {
-		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, false)
+		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, NavigateOptions{Limit: 1})
 	}
 }
 
 func BenchmarkNavigateByTimeAll(b *testing.B) {
-	var navigatorForBenchmark = NewNavigator()
+	var navigatorForBenchmark = defaultNavigator()
 	var source, destination = "Botanic Garden", "Promenade"
-	var travelTime, _ = time.Parse("2006-01-02T15:04", "2020-11-09T06:01")
+	var travelTime, _ = time.ParseInLocation("2006-01-02T15:04", "2020-11-09T06:01", networkLocation)
+
+	for i := 0; i < b.N; i++ {
+		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, NavigateOptions{Limit: 3})
+	}
+}
+
+func BenchmarkNavigateByStopsDijkstra(b *testing.B) {
+	var navigatorForBenchmark = defaultNavigator()
+	var source, destination = "Botanic Gardens", "Promenade"
+
+	for i := 0; i < b.N; i++ {
+		navigatorForBenchmark.NavigateByStops(source, destination, NavigateOptions{Algorithm: AlgorithmDijkstra})
+	}
+}
+
+func BenchmarkNavigateByTimeDijkstra(b *testing.B) {
+	var navigatorForBenchmark = defaultNavigator()
+	var source, destination = "Botanic Gardens", "Promenade"
+	var travelTime, _ = time.ParseInLocation("2006-01-02T15:04", "2020-11-09T06:01", networkLocation)
+
+	for i := 0; i < b.N; i++ {
+		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, NavigateOptions{Algorithm: AlgorithmDijkstra})
+	}
+}
+
+func BenchmarkNavigateByStopsBidirectional(b *testing.B) {
+	var navigatorForBenchmark = defaultNavigator()
+	var source, destination = "Botanic Gardens", "Promenade"
+
+	for i := 0; i < b.N; i++ {
+		navigatorForBenchmark.NavigateByStops(source, destination, NavigateOptions{Algorithm: AlgorithmBidirectional})
+	}
+}
+
+func BenchmarkNavigateByStopsAStar(b *testing.B) {
+	var navigatorForBenchmark = defaultNavigator()
+	var source, destination = "Botanic Gardens", "Promenade"
+
+	for i := 0; i < b.N; i++ {
+		navigatorForBenchmark.NavigateByStops(source, destination, NavigateOptions{Algorithm: AlgorithmAStar})
+	}
+}
+
+func BenchmarkNavigateByTimeAStar(b *testing.B) {
+	var navigatorForBenchmark = defaultNavigator()
+	var source, destination = "Botanic Gardens", "Promenade"
+	var travelTime, _ = time.ParseInLocation("2006-01-02T15:04", "2020-11-09T06:01", networkLocation)
 
 	for i := 0; i < b.N; i++ {
-		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, true)
+		navigatorForBenchmark.NavigateByTime(source, destination, travelTime, NavigateOptions{Algorithm: AlgorithmAStar})
 	}
 }
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// Network is the data a Navigator is built from: the Stations, the line topology by
+// Segments, the running times of Segments, the walking times of interchanges, the
+// station coordinates, the aliases of station names, the public holidays, the Schedule
+// of travel periods and the first and last train times
+type Network struct {
+	stations         []Station
+	segments         []Segment
+	segmentTimes     []SegmentTime
+	interchangeTimes []InterchangeTime
+	coordinates      map[StationID]Coordinates
+	aliases          map[string]string
+	holidays         []Holiday
+	schedule         Schedule
+	serviceHours     []ServiceHours
+}
+
+// StationSource provides the Network to build a Navigator from
+type StationSource interface {
+	Network() (Network, error)
+}
+
+// embeddedData holds the csv and json files in ./data compiled into the binary
+//
+//go:embed data/*.csv data/*.json
+var embeddedData embed.FS
+
+// DataSource reads the Network from the csv files of a directory in the file system,
+// where StationMap.csv and LineSegments.csv are required, while SegmentTimes.csv,
+// InterchangeTimes.csv, StationCoordinates.csv, StationAliases.csv, PublicHolidays.csv,
+// PublicHolidays.ics, Schedule.json and ServiceHours.csv are optional
+type DataSource struct {
+	FS fs.FS
+}
+
+// Network implements StationSource interface
+func (s DataSource) Network() (Network, error) {
+	source := ReaderSource{}
+	// the files are read by ReaderSource after all of them are opened
+	opened := []fs.File{}
+	defer func() {
+		for _, f := range opened {
+			f.Close()
+		}
+	}()
+	for _, file := range []struct {
+		name     string
+		reader   *io.Reader
+		required bool
+	}{
+		{"StationMap.csv", &source.Stations, true},
+		{"LineSegments.csv", &source.Segments, true},
+		{"SegmentTimes.csv", &source.SegmentTimes, false},
+		{"InterchangeTimes.csv", &source.InterchangeTimes, false},
+		{"StationCoordinates.csv", &source.Coordinates, false},
+		{"StationAliases.csv", &source.Aliases, false},
+		{"PublicHolidays.csv", &source.Holidays, false},
+		{"PublicHolidays.ics", &source.HolidaysICalendar, false},
+		{"Schedule.json", &source.Schedule, false},
+		{"ServiceHours.csv", &source.ServiceHours, false},
+	} {
+		f, err := s.FS.Open(file.name)
+		if err != nil {
+			if !file.required && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return Network{}, err
+		}
+		opened = append(opened, f)
+		*file.reader = f
+	}
+	return source.Network()
+}
+
+// ReaderSource reads the Network from csv and json files in the format of those in ./data,
+// and the public holidays also from an iCalendar file, where the Stations and Segments are
+// required, the Schedule is the default one of ./data if nil, and the others are left
+// empty if nil
+type ReaderSource struct {
+	Stations          io.Reader
+	Segments          io.Reader
+	SegmentTimes      io.Reader
+	InterchangeTimes  io.Reader
+	Coordinates       io.Reader
+	Aliases           io.Reader
+	Holidays          io.Reader
+	HolidaysICalendar io.Reader
+	Schedule          io.Reader
+	ServiceHours      io.Reader
+}
+
+// Network implements StationSource interface
+func (s ReaderSource) Network() (Network, error) {
+	if s.Stations == nil || s.Segments == nil {
+		return Network{}, errors.New("stations and segments are required")
+	}
+
+	var network Network
+	var err error
+	if network.stations, err = ReadStations(s.Stations); err != nil {
+		return Network{}, fmt.Errorf("stations: %v", err)
+	}
+	if network.segments, err = ReadSegments(s.Segments); err != nil {
+		return Network{}, fmt.Errorf("segments: %v", err)
+	}
+	if s.SegmentTimes != nil {
+		if network.segmentTimes, err = ReadSegmentTimes(s.SegmentTimes); err != nil {
+			return Network{}, fmt.Errorf("segment times: %v", err)
+		}
+	}
+	if s.InterchangeTimes != nil {
+		if network.interchangeTimes, err = ReadInterchangeTimes(s.InterchangeTimes); err != nil {
+			return Network{}, fmt.Errorf("interchange times: %v", err)
+		}
+	}
+	network.coordinates = make(map[StationID]Coordinates)
+	if s.Coordinates != nil {
+		if network.coordinates, err = ReadCoordinates(s.Coordinates); err != nil {
+			return Network{}, fmt.Errorf("coordinates: %v", err)
+		}
+	}
+	network.aliases = make(map[string]string)
+	if s.Aliases != nil {
+		if network.aliases, err = ReadAliases(s.Aliases); err != nil {
+			return Network{}, fmt.Errorf("aliases: %v", err)
+		}
+	}
+	if s.Holidays != nil {
+		if network.holidays, err = ReadHolidays(s.Holidays); err != nil {
+			return Network{}, fmt.Errorf("holidays: %v", err)
+		}
+	}
+	if s.HolidaysICalendar != nil {
+		holidays, err := ReadICalendar(s.HolidaysICalendar)
+		if err != nil {
+			return Network{}, fmt.Errorf("holidays: %v", err)
+		}
+		network.holidays = append(network.holidays, holidays...)
+	}
+	network.schedule = defaultSchedule()
+	if s.Schedule != nil {
+		if network.schedule, err = ReadSchedule(s.Schedule); err != nil {
+			return Network{}, fmt.Errorf("schedule: %v", err)
+		}
+	}
+	if s.ServiceHours != nil {
+		if network.serviceHours, err = ReadServiceHours(s.ServiceHours); err != nil {
+			return Network{}, fmt.Errorf("service hours: %v", err)
+		}
+	}
+	return network, nil
+}
+
+// GTFSSource reads the Network from a GTFS feed at the path, either a zip file or a
+// directory, with the default Schedule of ./data
+type GTFSSource string
+
+// Network implements StationSource interface
+func (path GTFSSource) Network() (Network, error) {
+	network, err := ReadGTFS(string(path))
+	if err != nil {
+		return Network{}, err
+	}
+	network.schedule = defaultSchedule()
+	return network, nil
+}
+
+// NavigatorOption customises the StationSource a Navigator is built from
+type NavigatorOption func(*navigatorConfig)
+
+// navigatorConfig is the configuration of NewNavigator set by NavigatorOptions
+type navigatorConfig struct {
+	source StationSource
+}
+
+// newNavigatorConfig is a helper function to apply the options on the default
+// configuration, which builds from the csv files embedded from ./data
+func newNavigatorConfig(opts []NavigatorOption) navigatorConfig {
+	config := navigatorConfig{source: defaultSource()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithDataDir builds the Navigator from the csv files in the directory, see DataSource
+func WithDataDir(dir string) NavigatorOption {
+	return WithStationSource(DataSource{FS: os.DirFS(dir)})
+}
+
+// WithReaders builds the Navigator from the csv files read from io.Readers, see
+// ReaderSource
+func WithReaders(source ReaderSource) NavigatorOption {
+	return WithStationSource(source)
+}
+
+// WithGTFS builds the Navigator from the GTFS feed at the path, see GTFSSource
+func WithGTFS(path string) NavigatorOption {
+	return WithStationSource(GTFSSource(path))
+}
+
+// WithStationSource builds the Navigator from the Network provided by the StationSource
+func WithStationSource(source StationSource) NavigatorOption {
+	return func(config *navigatorConfig) {
+		config.source = source
+	}
+}
+
+// defaultSource is the StationSource of the csv files embedded from ./data
+func defaultSource() StationSource {
+	data, err := fs.Sub(embeddedData, "data")
+	if err != nil {
+		// unreachable as the directory is embedded
+		panic(err)
+	}
+	return DataSource{FS: data}
+}
+
+// validate checks that the Schedule of the Network has travel periods, and the Segments,
+// running times, interchange times, coordinates and aliases and service hours only
+// reference its Stations, Segments and travel periods
+func (network Network) validate() error {
+	if err := validateSegments(network.stations, network.segments); err != nil {
+		return err
+	}
+	if err := validateSegmentTimes(network.segments, network.segmentTimes); err != nil {
+		return err
+	}
+	if err := validateInterchangeTimes(network.stations, network.interchangeTimes); err != nil {
+		return err
+	}
+	if err := validatePeriods(network.schedule, network.segmentTimes, network.interchangeTimes); err != nil {
+		return err
+	}
+	if err := validateCoordinates(network.stations, network.coordinates); err != nil {
+		return err
+	}
+	if err := validateServiceHours(network.stations, network.segments, network.serviceHours); err != nil {
+		return err
+	}
+	return validateAliases(network.stations, network.aliases)
+}
+
+// travelCosts prices the Network for each travel period of the Schedule, preferring the
+// time of each segment and interchange over the flat costs of the period
+func (network Network) travelCosts() map[string]TravelCost {
+	travelCosts := make(map[string]TravelCost)
+	for _, period := range network.schedule.periodNames() {
+		travelCosts[period] = newTravelCostBySegment(network.segmentTimes, network.interchangeTimes, period, network.schedule.travelCost(period))
+	}
+	return travelCosts
+}
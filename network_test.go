@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	testStationMap   = "Station Code,Station Name,Opening Date\nNS1,Jurong East,10 March 1990\nNS2,Bukit Batok,10 March 1990\nEW24,Jurong East,5 November 1988\n"
+	testLineSegments = "From,To\nNS1,NS2\n"
+)
+
+func TestDataSource(t *testing.T) {
+	network, err := DataSource{FS: os.DirFS("./data")}.Network()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(network, defaultNetwork()) {
+		t.Errorf("expect same Network from ./data as embedded")
+	}
+
+	// optional files are left empty
+	network, err = DataSource{FS: fstest.MapFS{
+		"StationMap.csv":   &fstest.MapFile{Data: []byte(testStationMap)},
+		"LineSegments.csv": &fstest.MapFile{Data: []byte(testLineSegments)},
+	}}.Network()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.stations) != 3 || len(network.segments) != 1 || len(network.segmentTimes) != 0 || len(network.interchangeTimes) != 0 {
+		t.Errorf("unexpected Network: %v", network)
+	}
+
+	// required files are not
+	if _, err := (DataSource{FS: fstest.MapFS{
+		"StationMap.csv": &fstest.MapFile{Data: []byte(testStationMap)},
+	}}).Network(); err == nil {
+		t.Errorf("expect error without LineSegments.csv")
+	}
+}
+
+func TestNewNavigatorWithReaders(t *testing.T) {
+	n, err := NewNavigator(WithReaders(ReaderSource{
+		Stations:         strings.NewReader(testStationMap),
+		Segments:         strings.NewReader(testLineSegments),
+		InterchangeTimes: strings.NewReader("From,To,Period,Minutes\nNS1,EW24,,4\n"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, err := n.NavigateByStops("EW24", "Bukit Batok", NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Weight != 2 {
+		t.Errorf("expected weight: 2, actual: %v", routes)
+	}
+}
+
+func TestNewNavigatorError(t *testing.T) {
+	for _, testCase := range []struct {
+		name string
+		opts []NavigatorOption
+	}{
+		{
+			name: "missing directory",
+			opts: []NavigatorOption{WithDataDir("./nonexistent")},
+		},
+		{
+			name: "missing GTFS feed",
+			opts: []NavigatorOption{WithGTFS("./nonexistent.zip")},
+		},
+		{
+			name: "missing segments",
+			opts: []NavigatorOption{WithReaders(ReaderSource{Stations: strings.NewReader(testStationMap)})},
+		},
+		{
+			name: "malformed stations",
+			opts: []NavigatorOption{WithReaders(ReaderSource{
+				Stations: strings.NewReader("Station Code,Station Name,Opening Date\nNS1,Jurong East\n"),
+				Segments: strings.NewReader(testLineSegments),
+			})},
+		},
+		{
+			name: "segment of unknown station",
+			opts: []NavigatorOption{WithReaders(ReaderSource{
+				Stations: strings.NewReader(testStationMap),
+				Segments: strings.NewReader("From,To\nNS1,NS3\n"),
+			})},
+		},
+		{
+			name: "segment time of unknown period",
+			opts: []NavigatorOption{WithReaders(ReaderSource{
+				Stations:     strings.NewReader(testStationMap),
+				Segments:     strings.NewReader(testLineSegments),
+				SegmentTimes: strings.NewReader("From,To,Period,Minutes\nNS1,NS2,rush,4\n"),
+			})},
+		},
+		{
+			name: "interchange time of unknown period",
+			opts: []NavigatorOption{WithReaders(ReaderSource{
+				Stations:         strings.NewReader(testStationMap),
+				Segments:         strings.NewReader(testLineSegments),
+				InterchangeTimes: strings.NewReader("From,To,Period,Minutes\nNS1,EW24,rush,6\n"),
+			})},
+		},
+		{
+			name: "malformed schedule",
+			opts: []NavigatorOption{WithReaders(ReaderSource{
+				Stations: strings.NewReader(testStationMap),
+				Segments: strings.NewReader(testLineSegments),
+				Schedule: strings.NewReader(`{"periods": []}`),
+			})},
+		},
+		{
+			name: "custom source without schedule",
+			opts: []NavigatorOption{WithStationSource(networkSource(func() Network {
+				network := defaultNetwork()
+				network.schedule = Schedule{}
+				return network
+			}()))},
+		},
+	} {
+		if _, err := NewNavigator(testCase.opts...); err == nil {
+			t.Errorf("%s expect error", testCase.name)
+		}
+	}
+}
+
+// networkSource is a StationSource of the given Network
+type networkSource Network
+
+// Network implements StationSource interface
+func (s networkSource) Network() (Network, error) {
+	return Network(s), nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import "container/heap"
+
+// Criteria is a vector of weights minimised together by multi-criteria searches,
+// eg. travel time and number of interchanges.
+type Criteria []Weight
+
+// dominates tells if c is no worse than other in every criterion
+func (c Criteria) dominates(other Criteria) bool {
+	for i := range c {
+		if c[i] > other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// less compares two Criteria in lexicographic order
+func (c Criteria) less(other Criteria) bool {
+	for i := range c {
+		if c[i] != other[i] {
+			return c[i] < other[i]
+		}
+	}
+	return false
+}
+
+// CriteriaFunc gives the Criteria of the edge from u to v, knowing the static weight w
+// stored in the Graph and the Criteria accumulated at u when the edge is taken.
+// It returns false when the edge cannot be taken at that moment.
+type CriteriaFunc func(u, v VertexID, w Weight, at Criteria) (Criteria, bool)
+
+// ParetoPath is a Path found by multi-criteria searches with its Criteria
+type ParetoPath struct {
+	Path
+	Criteria Criteria
+}
+
+// label is a partial path to a vertex in multi-criteria searches
+type label struct {
+	vertex   int
+	criteria Criteria
+	parent   *label
+}
+
+// ParetoPaths finds the Pareto-optimal paths from source to destination in a Graph,
+// ie. no other path is better in some criterion and no worse in all the others, using
+// a label-setting algorithm. Among paths with equal Criteria only one is kept.
+// The Criteria of all edges must be non-negative, and the Weight of each Path is its
+// first criterion. The paths are sorted by Criteria in lexicographic order.
+// It returns error when
+// 1) source or destination does not exist in the Graph;
+// 2) source and destination are the same;
+// 3) no path is found.
+func (g *Graph) ParetoPaths(src, dest VertexID, start Criteria, cost CriteriaFunc) ([]ParetoPath, error) {
+	if err := validate(g, src, dest); err != nil {
+		return nil, err
+	}
+	s, d := g.index[src], g.index[dest]
+
+	// permanent labels of each vertex, which do not dominate each other
+	permanent := make([][]*label, len(g.ids))
+	isDominated := func(v int, c Criteria) bool {
+		for _, l := range permanent[v] {
+			if l.criteria.dominates(c) {
+				return true
+			}
+		}
+		return false
+	}
+
+	queue := &labelQueue{&label{vertex: s, criteria: start}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(*label)
+		if isDominated(current.vertex, current.criteria) {
+			continue
+		}
+		permanent[current.vertex] = append(permanent[current.vertex], current)
+		// paths via destination can never be better
+		if current.vertex == d {
+			continue
+		}
+
+		for _, e := range g.adjacency[current.vertex] {
+			c, ok := cost(g.ids[current.vertex], g.ids[e.to], e.weight, current.criteria)
+			if !ok {
+				continue
+			}
+			next := make(Criteria, len(c))
+			for i := range c {
+				next[i] = current.criteria[i] + c[i]
+			}
+			// prune labels dominated at the neighbor or by those reaching destination
+			if isDominated(e.to, next) || isDominated(d, next) {
+				continue
+			}
+			heap.Push(queue, &label{vertex: e.to, criteria: next, parent: current})
+		}
+	}
+
+	if len(permanent[d]) == 0 {
+		return nil, ErrorPathNotFound
+	}
+	paths := []ParetoPath{}
+	for _, l := range permanent[d] {
+		stops := []Vertex{}
+		for p := l; p != nil; p = p.parent {
+			stops = append([]Vertex{*g.Vertices[g.ids[p.vertex]]}, stops...)
+		}
+		paths = append(paths, ParetoPath{
+			Path:     Path{Stops: stops, Weight: l.criteria[0]},
+			Criteria: l.criteria,
+		})
+	}
+	return paths, nil
+}
+
+// labelQueue implements heap.Interface as a min-heap of labels by Criteria in
+// lexicographic order, and the vertex number breaks ties.
+type labelQueue []*label
+
+// Len implements sort.Interface
+func (q labelQueue) Len() int { return len(q) }
+
+// Less implements sort.Interface
+func (q labelQueue) Less(i, j int) bool {
+	if q[i].criteria.less(q[j].criteria) {
+		return true
+	}
+	if q[j].criteria.less(q[i].criteria) {
+		return false
+	}
+	return q[i].vertex < q[j].vertex
+}
+
+// Swap implements sort.Interface
+func (q labelQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Push implements heap.Interface
+func (q *labelQueue) Push(x interface{}) {
+	*q = append(*q, x.(*label))
+}
+
+// Pop implements heap.Interface
+func (q *labelQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
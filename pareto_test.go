@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParetoPaths(t *testing.T) {
+	// criteria of (weight, edges): 1-2-3-4 is the lightest, 1-5-4 has fewest edges,
+	// and 1-6-4 is dominated by 1-5-4
+	g := NewGraph().
+		LinkBoth(IntVertex(1), IntVertex(2), 1).
+		LinkBoth(IntVertex(2), IntVertex(3), 1).
+		LinkBoth(IntVertex(3), IntVertex(4), 1).
+		LinkBoth(IntVertex(1), IntVertex(5), 2).
+		LinkBoth(IntVertex(5), IntVertex(4), 2).
+		LinkBoth(IntVertex(1), IntVertex(6), 3).
+		LinkBoth(IntVertex(6), IntVertex(4), 3)
+	cost := func(_, _ VertexID, w Weight, _ Criteria) (Criteria, bool) {
+		return Criteria{w, 1}, true
+	}
+	expected := []ParetoPath{
+		ParetoPath{
+			Path:     Path{Stops: []Vertex{IntVertex(1), IntVertex(2), IntVertex(3), IntVertex(4)}, Weight: 3},
+			Criteria: Criteria{3, 3},
+		},
+		ParetoPath{
+			Path:     Path{Stops: []Vertex{IntVertex(1), IntVertex(5), IntVertex(4)}, Weight: 4},
+			Criteria: Criteria{4, 2},
+		},
+	}
+	actual, err := g.ParetoPaths(1, 4, Criteria{0, 0}, cost)
+	if err != nil {
+		t.Errorf("expected: %v, actual error: %s", expected, err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestParetoPathsError(t *testing.T) {
+	cost := func(_, _ VertexID, w Weight, _ Criteria) (Criteria, bool) {
+		return Criteria{w}, true
+	}
+	for _, testCase := range expectedError {
+		_, err := testCase.g.ParetoPaths(testCase.src, testCase.dest, Criteria{0}, cost)
+		if err == nil {
+			t.Errorf("expect error on %s", testCase.name)
+		}
+	}
+}
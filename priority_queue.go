@@ -0,0 +1,41 @@
+package main
+
+// queueItem is a vertex waiting in priorityQueue with its tentative weight.
+// The vertex is referred by its number in Graph, which also breaks ties of equal weights.
+type queueItem struct {
+	vertex int
+	weight Weight
+}
+
+// priorityQueue implements heap.Interface as a min-heap of queueItems. Path-finding
+// algorithms push a vertex again when its weight decreases, and skip the stale items
+// of visited vertices when popped (lazy deletion).
+type priorityQueue []queueItem
+
+// Len implements sort.Interface
+func (q priorityQueue) Len() int { return len(q) }
+
+// Less implements sort.Interface
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight < q[j].weight
+	}
+	return q[i].vertex < q[j].vertex
+}
+
+// Swap implements sort.Interface
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Push implements heap.Interface
+func (q *priorityQueue) Push(x interface{}) {
+	*q = append(*q, x.(queueItem))
+}
+
+// Pop implements heap.Interface
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
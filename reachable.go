@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// Reachable returns the fastest paths to every Station reachable from the source within
+// the budget of minutes, knowing the time of travel, or any error encountered. Like
+// NavigateByTime, only the Stations opened at the time of travel are considered and the
+// cost of each part of the journey is evaluated at the time it is reached. It accepts
+// source input as string, which can be either StationID like "DT1" or station name like
+// "Bukit Panjang". The paths are ordered by estimated time, one for each StationID.
+func (n *Navigator) Reachable(srcStr string, t time.Time, budget Weight) ([]Path, error) {
+	t = t.In(networkLocation)
+	return n.graphAt(t).reachable(srcStr, t, budget)
+}
+
+// reachable is a helper function which searches the fastest paths to every Station of the
+// cached Graph reachable from the source within the budget, departing at the given time
+func (c cachedGraph) reachable(srcStr string, t time.Time, budget Weight) ([]Path, error) {
+	allSrc, _, err := searchStations(c.stations, c.aliases, srcStr)
+	if err != nil {
+		return nil, ErrorSourceNotFound
+	}
+
+	paths := []Path{}
+	for _, src := range allSrc {
+		// the whole Graph is searched, as a Station reached by an interchange is settled
+		// with the wait for the next train, which is not taken when the journey ends there
+		ps, err := c.graph.TimeDependentReachable(src, -1, c.timeDependentWeight(t, src, nil, false))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			if len(p.Stops) > 1 {
+				p.Weight, _ = c.graph.Evaluate(p.Stops, c.pathWeight(p, t))
+			}
+			if budget < 0 || p.Weight <= budget {
+				paths = append(paths, p)
+			}
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].Weight < paths[j].Weight })
+
+	// keep the fastest path to each Station from any of the source Stations
+	reached := make(map[StationID]bool)
+	result := []Path{}
+	for _, p := range paths {
+		id := p.Stops[len(p.Stops)-1].(Station).id
+		if !reached[id] {
+			reached[id] = true
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import "errors"
+
+// ErrorNoStationSource is returned when reloading a Navigator not built by NewNavigator
+var ErrorNoStationSource = errors.New("no station source to reload from")
+
+// Reload reads the Network again from the StationSource the Navigator was built from and
+// validates it, then atomically swaps it in with an empty cache of Graphs. Requests in
+// flight finish on the Graphs they already hold, and the current Network is kept if the
+// new one fails to load or validate. A ReaderSource can not be reloaded once its readers
+// are consumed.
+func (n *Navigator) Reload() error {
+	if n.source == nil {
+		return ErrorNoStationSource
+	}
+	network, err := n.source.Network()
+	if err != nil {
+		return err
+	}
+	if err := network.validate(); err != nil {
+		return err
+	}
+	n.setNetwork(network)
+	return nil
+}
+
+// stationCount returns the number of Stations in the current Network
+func (n *Navigator) stationCount() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.allStations)
+}
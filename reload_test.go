@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(stationMap, lineSegments string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "StationMap.csv"), []byte(stationMap), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "LineSegments.csv"), []byte(lineSegments), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(testStationMap, testLineSegments)
+	n, err := NewNavigator(WithDataDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.NavigateByStops("Jurong East", "Clementi", NavigateOptions{}); err != ErrorDestinationNotFound {
+		t.Errorf("expected error: %v, actual: %v", ErrorDestinationNotFound, err)
+	}
+	inFlight := n.graphByStops()
+
+	// a new station opens
+	write(testStationMap+"EW23,Clementi,12 March 1988\n", testLineSegments+"EW24,EW23\n")
+	if err := n.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := n.NavigateByStops("Jurong East", "Clementi", NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Weight != 1 {
+		t.Errorf("expected weight: 1, actual: %v", routes)
+	}
+	if len(inFlight.allStations) != 3 || inFlight.graph == n.graphByStops().graph {
+		t.Errorf("expect in-flight Graph unchanged after reload")
+	}
+
+	// a bad file never replaces the good one
+	write(testStationMap, testLineSegments+"EW24,EW23\n")
+	if err := n.Reload(); err == nil || !strings.Contains(err.Error(), "unknown station") {
+		t.Errorf("expect error of unknown station, actual: %v", err)
+	}
+	if _, err := n.NavigateByStops("Jurong East", "Clementi", NavigateOptions{}); err != nil {
+		t.Errorf("expect network kept after failed reload, actual: %v", err)
+	}
+	if n.stationCount() != 4 {
+		t.Errorf("expected stations: 4, actual: %d", n.stationCount())
+	}
+
+	if err := newNavigator(defaultNetwork()).Reload(); err != ErrorNoStationSource {
+		t.Errorf("expected error: %v, actual: %v", ErrorNoStationSource, err)
+	}
+}
+
+func TestHandleReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "StationMap.csv"), []byte(testStationMap), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "LineSegments.csv"), []byte(testLineSegments), 0644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewNavigator(WithDataDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		n        *Navigator
+		method   string
+		expected int
+		body     string
+	}{
+		{"reloaded", n, http.MethodPost, http.StatusOK, `{"stations":3}`},
+		{"not post", n, http.MethodGet, http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+		{"no source", newNavigator(defaultNetwork()), http.MethodPost, http.StatusInternalServerError, `{"error":"` + ErrorNoStationSource.Error() + `"}`},
+	} {
+		w := httptest.NewRecorder()
+		testCase.n.handleReload(w, httptest.NewRequest(testCase.method, "/admin/reload", nil))
+		if w.Code != testCase.expected || w.Body.String() != testCase.body {
+			t.Errorf("%s expected: %d %s, actual: %d %s", testCase.name, testCase.expected, testCase.body, w.Code, w.Body)
+		}
+	}
+
+	// a bad file fails the reload with the current network kept
+	if err := ioutil.WriteFile(filepath.Join(dir, "LineSegments.csv"), []byte(testLineSegments+"EW24,EW23\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	n.handleReload(w, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "unknown station") || n.stationCount() != 3 {
+		t.Errorf("expected: %d with unknown station, actual: %d %s", http.StatusInternalServerError, w.Code, w.Body)
+	}
+}
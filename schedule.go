@@ -0,0 +1,426 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dayHoliday is the day type of public holidays, and of their eves from noon, in the
+// windows of a Schedule
+const dayHoliday = "holiday"
+
+// scheduleDays are the day types a window of a Schedule may apply to
+var scheduleDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", dayHoliday}
+
+// minutesPerDay is the number of minutes in a day
+const minutesPerDay = 24 * 60
+
+// defaultWaitFactor is the expected wait for a train as a fraction of its headway, ie.
+// half the headway for passengers arriving at random
+const defaultWaitFactor = 0.5
+
+// Schedule defines the travel periods by the day types, time of day and dates they apply
+// to, where every minute falls in exactly one period. Each period has its own flat
+// TravelCostByTime, with the expected wait for boarding by the headways of lines, and the
+// lines not operating in it.
+type Schedule struct {
+	periods []SchedulePeriod
+}
+
+// SchedulePeriod is a named travel period of a Schedule
+type SchedulePeriod struct {
+	name           string
+	windows        []scheduleWindow
+	cost           TravelCostByTime
+	headways       map[string]Weight
+	headwayDefault Weight
+	closedLines    map[string]bool
+}
+
+// scheduleWindow is the time range of a period on some day types, from the minute start
+// until before the minute end of the day, and optionally only in the date range from and
+// until inclusive
+type scheduleWindow struct {
+	days  map[string]bool
+	start int
+	end   int
+	from  time.Time
+	until time.Time
+}
+
+// scheduleFile is the json format of a Schedule
+type scheduleFile struct {
+	Periods []struct {
+		Name    string `json:"name"`
+		Windows []struct {
+			Days  []string `json:"days"`
+			Start string   `json:"start"`
+			End   string   `json:"end"`
+			From  string   `json:"from"`
+			Until string   `json:"until"`
+		} `json:"windows"`
+		Cost struct {
+			Interchange int            `json:"interchange"`
+			Lines       map[string]int `json:"lines"`
+			Default     int            `json:"default"`
+		} `json:"cost"`
+		Headways struct {
+			Lines   map[string]int `json:"lines"`
+			Default int            `json:"default"`
+		} `json:"headways"`
+		ClosedLines []string `json:"closed_lines"`
+	} `json:"periods"`
+	WaitFactor *float64 `json:"wait_factor"`
+}
+
+// ReadSchedule reads the Schedule from the given io.Reader, and returns error if any
+// minute is in no period or in more than one.
+// It assumes the format being:
+/*
+{
+  "periods": [
+    {
+      "name": "night",
+      "windows": [
+        {"days": ["saturday", "sunday", "holiday"], "start": "00:00", "end": "06:00"},
+        {"days": ["monday"], "start": "22:00", "end": "24:00", "from": "2021-01-01", "until": "2021-12-31"}
+      ],
+      "cost": {"interchange": 10, "lines": {"TE": 8}, "default": 10},
+      "headways": {"lines": {"CG": 10}, "default": 7},
+      "closed_lines": ["DT", "CE"]
+    }
+  ],
+  "wait_factor": 0.5
+}
+*/
+// The days are the days of week in lowercase, and holiday for public holidays and the
+// eves of them from noon, which are otherwise scheduled by their days of week unless
+// some window applies to holiday. The expected wait for boarding a train is its headway
+// in minutes times the wait factor, rounded, which is half the headway by default, and no
+// wait without headways.
+func ReadSchedule(r io.Reader) (Schedule, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var file scheduleFile
+	if err := decoder.Decode(&file); err != nil {
+		return Schedule{}, err
+	}
+
+	// the format used by time.Parse function
+	const dateFormat string = "2006-01-02"
+
+	waitFactor := defaultWaitFactor
+	if file.WaitFactor != nil {
+		waitFactor = *file.WaitFactor
+	}
+	if waitFactor < 0 || waitFactor > 1 {
+		return Schedule{}, fmt.Errorf("wait factor %v not between 0 and 1", waitFactor)
+	}
+	wait := func(headway int) Weight {
+		return Weight(math.Round(float64(headway) * waitFactor))
+	}
+
+	schedule := Schedule{}
+	names := make(map[string]bool)
+	for _, p := range file.Periods {
+		if p.Name == "" {
+			return Schedule{}, errors.New("period without name")
+		}
+		if p.Name == periodByStops || names[p.Name] {
+			return Schedule{}, fmt.Errorf("duplicate period %s", p.Name)
+		}
+		names[p.Name] = true
+		if p.Cost.Interchange <= 0 || p.Cost.Default <= 0 {
+			return Schedule{}, fmt.Errorf("period %s: costs not positive", p.Name)
+		}
+
+		period := SchedulePeriod{
+			name: p.Name,
+			cost: TravelCostByTime{
+				interchange:     Weight(p.Cost.Interchange),
+				lines:           make(map[string]Weight),
+				lineDefault:     Weight(p.Cost.Default),
+				boarding:        make(map[string]Weight),
+				boardingDefault: wait(p.Headways.Default),
+			},
+			headways:       make(map[string]Weight),
+			headwayDefault: Weight(p.Headways.Default),
+			closedLines:    make(map[string]bool),
+		}
+		for line, w := range p.Cost.Lines {
+			if w <= 0 {
+				return Schedule{}, fmt.Errorf("period %s: cost of line %s not positive", p.Name, line)
+			}
+			period.cost.lines[strings.ToUpper(line)] = Weight(w)
+		}
+		if p.Headways.Default < 0 {
+			return Schedule{}, fmt.Errorf("period %s: headway negative", p.Name)
+		}
+		for line, headway := range p.Headways.Lines {
+			if headway < 0 {
+				return Schedule{}, fmt.Errorf("period %s: headway of line %s negative", p.Name, line)
+			}
+			period.headways[strings.ToUpper(line)] = Weight(headway)
+			period.cost.boarding[strings.ToUpper(line)] = wait(headway)
+		}
+		for _, line := range p.ClosedLines {
+			period.closedLines[strings.ToUpper(line)] = true
+		}
+
+		for _, w := range p.Windows {
+			window := scheduleWindow{days: make(map[string]bool)}
+			for _, day := range w.Days {
+				if !containsString(scheduleDays, day) {
+					return Schedule{}, fmt.Errorf("period %s: unknown day %s", p.Name, day)
+				}
+				window.days[day] = true
+			}
+			var err error
+			if window.start, err = parseScheduleClock(w.Start); err != nil {
+				return Schedule{}, fmt.Errorf("period %s: %v", p.Name, err)
+			}
+			if window.end, err = parseScheduleClock(w.End); err != nil {
+				return Schedule{}, fmt.Errorf("period %s: %v", p.Name, err)
+			}
+			if window.start >= window.end {
+				return Schedule{}, fmt.Errorf("period %s: window %s-%s ends before it starts", p.Name, w.Start, w.End)
+			}
+			if w.From != "" {
+				if window.from, err = time.Parse(dateFormat, w.From); err != nil {
+					return Schedule{}, fmt.Errorf("period %s: %v", p.Name, err)
+				}
+			}
+			if w.Until != "" {
+				if window.until, err = time.Parse(dateFormat, w.Until); err != nil {
+					return Schedule{}, fmt.Errorf("period %s: %v", p.Name, err)
+				}
+			}
+			if !window.from.IsZero() && !window.until.IsZero() && window.until.Before(window.from) {
+				return Schedule{}, fmt.Errorf("period %s: window from %s until %s ends before it starts", p.Name, w.From, w.Until)
+			}
+			period.windows = append(period.windows, window)
+		}
+		schedule.periods = append(schedule.periods, period)
+	}
+
+	if err := schedule.validate(); err != nil {
+		return Schedule{}, err
+	}
+	return schedule, nil
+}
+
+// parseScheduleClock is a helper function to parse the time of day in format HH:MM into
+// minutes of the day, where 24:00 is the end of the day
+func parseScheduleClock(clock string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(clock, "%d:%d", &h, &m); err != nil || len(clock) != 5 {
+		return 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+	if h < 0 || m < 0 || m >= 60 || h*60+m > minutesPerDay {
+		return 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+	return h*60 + m, nil
+}
+
+// formatScheduleClock is a helper function to format minutes of the day as HH:MM
+func formatScheduleClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// validate checks that every minute of every day type falls in exactly one period, on
+// all dates. The dates are checked on each date a window starts or stops applying to,
+// and the day before the earliest of them, as nothing changes in between.
+func (s Schedule) validate() error {
+	if len(s.periods) == 0 {
+		return errors.New("no periods")
+	}
+
+	boundaries := []time.Time{}
+	for _, p := range s.periods {
+		for _, w := range p.windows {
+			if !w.from.IsZero() {
+				boundaries = append(boundaries, w.from)
+			}
+			if !w.until.IsZero() {
+				boundaries = append(boundaries, w.until.AddDate(0, 0, 1))
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	dates := []time.Time{}
+	if len(boundaries) == 0 {
+		dates = append(dates, time.Time{})
+	} else {
+		dates = append(dates, boundaries[0].AddDate(0, 0, -1))
+	}
+	for i, b := range boundaries {
+		if i == 0 || !b.Equal(boundaries[i-1]) {
+			dates = append(dates, b)
+		}
+	}
+
+	days := scheduleDays[:7]
+	if s.hasHolidays() {
+		days = scheduleDays
+	}
+	for i, date := range dates {
+		// describe the dates checked when the schedule varies by date
+		var when string
+		switch {
+		case len(boundaries) == 0:
+		case i == 0:
+			when = " before " + dates[1].Format("2006-01-02")
+		default:
+			when = " from " + date.Format("2006-01-02")
+		}
+		for _, day := range days {
+			for m := 0; m < minutesPerDay; m++ {
+				matched := []string{}
+				for _, p := range s.periods {
+					if p.appliesTo(day, m, date) {
+						matched = append(matched, p.name)
+					}
+				}
+				switch {
+				case len(matched) == 0:
+					return fmt.Errorf("no period on %s at %s%s", day, formatScheduleClock(m), when)
+				case len(matched) > 1:
+					return fmt.Errorf("periods %s overlap on %s at %s%s", strings.Join(matched, " and "), day, formatScheduleClock(m), when)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasHolidays checks if any window applies to public holidays, which are otherwise
+// scheduled by their days of week
+func (s Schedule) hasHolidays() bool {
+	for _, p := range s.periods {
+		for _, w := range p.windows {
+			if w.days[dayHoliday] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appliesTo checks if a window of the period applies to the minute of the day type on
+// the date, where a zero date matches any date range
+func (p SchedulePeriod) appliesTo(day string, minute int, date time.Time) bool {
+	for _, w := range p.windows {
+		if !w.days[day] || minute < w.start || minute >= w.end {
+			continue
+		}
+		if !date.IsZero() && (!w.from.IsZero() && date.Before(w.from) || !w.until.IsZero() && date.After(w.until)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// travelPeriod classifies the given time into the name of its period, where public
+// holidays of the Calendar, and their eves from noon, are of the holiday day type if
+// any window applies to it
+func (s Schedule) travelPeriod(t time.Time, calendar Calendar) string {
+	day := strings.ToLower(t.Weekday().String())
+	if s.hasHolidays() && calendar.isHoliday(t) {
+		day = dayHoliday
+	}
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	minute := t.Hour()*60 + t.Minute()
+	for _, p := range s.periods {
+		if p.appliesTo(day, minute, date) {
+			return p.name
+		}
+	}
+	// unreachable for a validated Schedule
+	return s.periods[0].name
+}
+
+// periodNames lists the names of all periods in order of definition
+func (s Schedule) periodNames() []string {
+	names := []string{}
+	for _, p := range s.periods {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// travelCost returns the flat TravelCostByTime of the named period
+func (s Schedule) travelCost(period string) TravelCostByTime {
+	for _, p := range s.periods {
+		if p.name == period {
+			return p.cost
+		}
+	}
+	return TravelCostByTime{}
+}
+
+// headway returns the minutes between trains of the MRT line in the named period, or 0
+// if unknown
+func (s Schedule) headway(period, line string) Weight {
+	for _, p := range s.periods {
+		if p.name == period {
+			if w, ok := p.headways[line]; ok {
+				return w
+			}
+			return p.headwayDefault
+		}
+	}
+	return 0
+}
+
+// isClosed checks if the MRT line does not operate in the named period
+func (s Schedule) isClosed(period, line string) bool {
+	for _, p := range s.periods {
+		if p.name == period {
+			return p.closedLines[line]
+		}
+	}
+	return false
+}
+
+// validatePeriods checks that the Schedule has any period, eg. not the zero Schedule of a
+// Network from a custom StationSource, and every running time and interchange time of a
+// period belongs to a period of the Schedule
+func validatePeriods(schedule Schedule, segmentTimes []SegmentTime, interchangeTimes []InterchangeTime) error {
+	if len(schedule.periods) == 0 {
+		return errors.New("schedule has no periods")
+	}
+	names := schedule.periodNames()
+	for _, st := range segmentTimes {
+		if st.period != "" && !containsString(names, st.period) {
+			return fmt.Errorf("running time for segment %s of unknown period %s", st.segment, st.period)
+		}
+	}
+	for _, it := range interchangeTimes {
+		if it.period != "" && !containsString(names, it.period) {
+			return fmt.Errorf("interchange time for %s-%s of unknown period %s", it.from, it.to, it.period)
+		}
+	}
+	return nil
+}
+
+// defaultSchedule is the Schedule embedded from ./data/Schedule.json
+func defaultSchedule() Schedule {
+	f, err := embeddedData.Open("data/Schedule.json")
+	if err != nil {
+		// unreachable as the file is embedded
+		panic(err)
+	}
+	defer f.Close()
+	schedule, err := ReadSchedule(f)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
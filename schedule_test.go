@@ -0,0 +1,203 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSchedule has a rush period on weekdays until 2020, a late period on weekdays from
+// 2021 and a quiet period otherwise, where the EW line is closed in the quiet period
+const testSchedule = `{
+  "periods": [
+    {
+      "name": "rush",
+      "windows": [
+        {"days": ["monday", "tuesday", "wednesday", "thursday", "friday"], "start": "07:30", "end": "09:00", "until": "2020-12-31"}
+      ],
+      "cost": {"interchange": 20, "lines": {"ns": 15}, "default": 12}
+    },
+    {
+      "name": "late",
+      "windows": [
+        {"days": ["monday", "tuesday", "wednesday", "thursday", "friday"], "start": "07:30", "end": "09:00", "from": "2021-01-01"}
+      ],
+      "cost": {"interchange": 12, "default": 11}
+    },
+    {
+      "name": "quiet",
+      "windows": [
+        {"days": ["monday", "tuesday", "wednesday", "thursday", "friday"], "start": "00:00", "end": "07:30"},
+        {"days": ["monday", "tuesday", "wednesday", "thursday", "friday"], "start": "09:00", "end": "24:00"},
+        {"days": ["saturday", "sunday"], "start": "00:00", "end": "24:00"}
+      ],
+      "cost": {"interchange": 10, "default": 10},
+      "closed_lines": ["EW"]
+    }
+  ]
+}`
+
+func TestReadSchedule(t *testing.T) {
+	schedule, err := ReadSchedule(strings.NewReader(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := schedule.periodNames(); strings.Join(names, ",") != "rush,late,quiet" {
+		t.Errorf("unexpected periods: %v", names)
+	}
+
+	// holidays are scheduled by their days of week without holiday windows
+	calendar := newCalendar([]Holiday{
+		Holiday{date: time.Date(2020, 8, 10, 0, 0, 0, 0, time.UTC), name: "National Day (observed)", kind: holidayKindHoliday},
+	})
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time     string
+		expected string
+	}{
+		{"2020-08-10T07:29", "quiet"},
+		{"2020-08-10T07:30", "rush"},
+		{"2020-08-10T08:59", "rush"},
+		{"2020-08-10T09:00", "quiet"},
+		{"2020-11-14T08:00", "quiet"},
+		{"2020-12-31T08:00", "rush"},
+		{"2021-01-01T08:00", "late"},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		if actual := schedule.travelPeriod(travelTime, calendar); actual != testCase.expected {
+			t.Errorf("%s expected: %s, actual: %s", testCase.time, testCase.expected, actual)
+		}
+	}
+
+	cost := schedule.travelCost("rush")
+	if w := cost.OnLine(StationID{"NS", 1}, StationID{"NS", 2}); w != 15 {
+		t.Errorf("expect rush cost 15 on NS line, actual: %d", w)
+	}
+	if w := cost.OnLine(StationID{"EW", 1}, StationID{"EW", 2}); w != 12 {
+		t.Errorf("expect rush cost 12 on EW line, actual: %d", w)
+	}
+	if !schedule.isClosed("quiet", "EW") || schedule.isClosed("rush", "EW") {
+		t.Errorf("expect EW line closed only in quiet period")
+	}
+}
+
+func TestReadScheduleHeadways(t *testing.T) {
+	content := strings.Replace(testSchedule, `"cost": {"interchange": 20, "lines": {"ns": 15}, "default": 12}`,
+		`"cost": {"interchange": 20, "lines": {"ns": 15}, "default": 12}, "headways": {"lines": {"ns": 2}, "default": 5}`, 1)
+	for _, testCase := range []struct {
+		waitFactor string
+		expectedNS Weight
+		expectedEW Weight
+	}{
+		{"", 1, 3},
+		{`, "wait_factor": 1`, 2, 5},
+		{`, "wait_factor": 0`, 0, 0},
+	} {
+		schedule, err := ReadSchedule(strings.NewReader(strings.TrimSuffix(content, "\n}") + testCase.waitFactor + "\n}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cost := schedule.travelCost("rush")
+		if actual := cost.Boarding(StationID{"NS", 1}); actual != testCase.expectedNS {
+			t.Errorf("wait factor %q expected wait on NS line: %d, actual: %d", testCase.waitFactor, testCase.expectedNS, actual)
+		}
+		if actual := cost.Boarding(StationID{"EW", 1}); actual != testCase.expectedEW {
+			t.Errorf("wait factor %q expected wait on EW line: %d, actual: %d", testCase.waitFactor, testCase.expectedEW, actual)
+		}
+		if schedule.headway("rush", "NS") != 2 || schedule.headway("rush", "EW") != 5 {
+			t.Errorf("unexpected headways: %d, %d", schedule.headway("rush", "NS"), schedule.headway("rush", "EW"))
+		}
+		if actual := schedule.travelCost("quiet").Boarding(StationID{"NS", 1}); actual != 0 {
+			t.Errorf("expected no wait without headways, actual: %d", actual)
+		}
+	}
+
+	for _, invalid := range []string{
+		strings.TrimSuffix(content, "\n}") + `, "wait_factor": 1.5` + "\n}",
+		strings.Replace(content, `"default": 5}`, `"default": -5}`, 1),
+	} {
+		if _, err := ReadSchedule(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expect error for input: %q", invalid)
+		}
+	}
+}
+
+func TestNavigateBoardingWait(t *testing.T) {
+	n := defaultNavigator()
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	for _, testCase := range []struct {
+		src      string
+		dest     string
+		via      []string
+		expected Weight
+	}{
+		// 25 minutes of the journey, with 2 minutes waiting at Bukit Batok and 2 more after
+		// changing to EW line at Jurong East
+		{"NS2", "EW23", nil, 29},
+		// 2 minutes waiting at Bukit Batok and none at the via station staying on board
+		{"NS2", "NS4", []string{"NS3"}, 26},
+	} {
+		routes, err := n.NavigateByTime(testCase.src, testCase.dest, travelTime, NavigateOptions{Via: testCase.via})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if routes[0].Weight != testCase.expected {
+			t.Errorf("%s to %s expected: %d, actual: %d", testCase.src, testCase.dest, testCase.expected, routes[0].Weight)
+		}
+	}
+}
+
+func TestReadScheduleError(t *testing.T) {
+	window := func(days, start, end string) string {
+		return `{"name": "p", "windows": [{"days": [` + days + `], "start": "` + start + `", "end": "` + end + `"}], "cost": {"interchange": 10, "default": 10}}`
+	}
+	allDays := `"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"`
+	for _, testCase := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"malformed", `{"periods": [`, "unexpected EOF"},
+		{"unknown field", `{"periods": [], "zones": []}`, "unknown field"},
+		{"no periods", `{"periods": []}`, "no periods"},
+		{"unknown day", `{"periods": [` + window(`"funday"`, "00:00", "24:00") + `]}`, "unknown day funday"},
+		{"invalid time", `{"periods": [` + window(allDays, "00:00", "25:00") + `]}`, "invalid time of day"},
+		{"empty window", `{"periods": [` + window(allDays, "09:00", "09:00") + `]}`, "ends before it starts"},
+		{"gap", `{"periods": [` + window(allDays, "00:00", "23:00") + `]}`, "no period on monday at 23:00"},
+		{"gap on holiday", `{"periods": [` + window(allDays, "00:00", "24:00") + `, ` + strings.Replace(window(`"holiday"`, "06:00", "22:00"), `"p"`, `"q"`, 1) + `]}`, "no period on holiday at 00:00"},
+		{"duplicate", `{"periods": [` + window(allDays, "00:00", "24:00") + `, ` + window(`"holiday"`, "00:00", "24:00") + `]}`, "duplicate period p"},
+		{"overlap", `{"periods": [` + window(allDays, "00:00", "24:00") + `, ` + strings.Replace(window(`"sunday"`, "22:00", "24:00"), `"p"`, `"q"`, 1) + `]}`, "periods p and q overlap on sunday at 22:00"},
+		{"gap by date", strings.Replace(testSchedule, `"from": "2021-01-01"`, `"from": "2021-02-01"`, 1), "no period on monday at 07:30 from 2021-01-01"},
+	} {
+		_, err := ReadSchedule(strings.NewReader(testCase.content))
+		if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+			t.Errorf("%s expect error %q, actual: %v", testCase.name, testCase.expected, err)
+		}
+	}
+}
+
+func TestNavigateBySchedule(t *testing.T) {
+	n, err := NewNavigator(WithReaders(ReaderSource{
+		Stations: strings.NewReader(testStationMap + "EW23,Clementi,12 March 1988\n"),
+		Segments: strings.NewReader(testLineSegments + "EW23,EW24\n"),
+		Schedule: strings.NewReader(testSchedule),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeLayout := "2006-01-02T15:04"
+	rush, _ := time.ParseInLocation(timeLayout, "2020-11-09T08:00", networkLocation)
+	quiet, _ := time.ParseInLocation(timeLayout, "2020-11-09T10:00", networkLocation)
+
+	routes, err := n.NavigateByTime("Bukit Batok", "Clementi", rush, NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 15 on NS line, 20 to interchange and 12 on EW line
+	if routes[0].Weight != 47 || strings.Join(routes[0].Periods, ",") != "rush" {
+		t.Errorf("unexpected route in rush period: %v", routes[0])
+	}
+	if _, err := n.NavigateByTime("Bukit Batok", "Clementi", quiet, NavigateOptions{}); err == nil {
+		t.Errorf("expect no route with EW line closed in quiet period")
+	}
+}
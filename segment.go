@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Segment is a track section directly connecting two Stations on the same MRT line.
+// It describes the line topology, so branches and loops can be modelled regardless
+// of how the stations are numbered.
+type Segment struct {
+	from StationID
+	to   StationID
+}
+
+// String implements Stringer interface
+func (s Segment) String() string {
+	return s.from.String() + "-" + s.to.String()
+}
+
+// ReadSegments reads the line segments from the given io.Reader.
+// It assumes the format being:
+/*
+From,To
+CG0,CG1
+CG1,CG2
+*/
+func ReadSegments(r io.Reader) ([]Segment, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := []Segment{}
+
+	for _, record := range records {
+		if len(record) != 2 {
+			return nil, fmt.Errorf("record length not 2: %v", record)
+		}
+		from, err := NewStationID(record[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := NewStationID(record[1])
+		if err != nil {
+			return nil, err
+		}
+		if from.line != to.line {
+			return nil, fmt.Errorf("segment %s-%s connects different lines", from, to)
+		}
+		if from == to {
+			return nil, fmt.Errorf("segment %s-%s connects station to itself", from, to)
+		}
+		final = append(final, Segment{from: from, to: to})
+	}
+
+	return final, nil
+}
+
+// validateSegments checks that every segment references stations in the station map.
+func validateSegments(stations []Station, segments []Segment) error {
+	known := make(map[StationID]bool)
+	for _, s := range stations {
+		known[s.id] = true
+	}
+	for _, seg := range segments {
+		for _, id := range []StationID{seg.from, seg.to} {
+			if !known[id] {
+				return fmt.Errorf("segment %s references unknown station %s", seg, id)
+			}
+		}
+	}
+	return nil
+}
+
+// SegmentTime is the running time in minutes on a Segment, in either direction.
+// An empty period means the time applies to all travel periods.
+type SegmentTime struct {
+	segment Segment
+	period  string
+	minutes Weight
+}
+
+// ReadSegmentTimes reads the running times of segments from the given io.Reader.
+// It assumes the format being:
+/*
+From,To,Period,Minutes
+NS27,NS28,,4
+NS27,NS28,peak,5
+*/
+func ReadSegmentTimes(r io.Reader) ([]SegmentTime, error) {
+	records, err := readStationPairTimes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	final := []SegmentTime{}
+
+	for _, record := range records {
+		if record.from.line != record.to.line {
+			return nil, fmt.Errorf("segment %s-%s connects different lines", record.from, record.to)
+		}
+		final = append(final, SegmentTime{
+			segment: Segment{from: record.from, to: record.to},
+			period:  record.period,
+			minutes: record.minutes,
+		})
+	}
+
+	return final, nil
+}
+
+// stationPairTime is a parsed row of a csv file with time between a pair of Stations
+type stationPairTime struct {
+	from    StationID
+	to      StationID
+	period  string
+	minutes Weight
+}
+
+// readStationPairTimes is a helper function to parse csv file in the format being:
+/*
+From,To,Period,Minutes
+*/
+func readStationPairTimes(r io.Reader) ([]stationPairTime, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := []stationPairTime{}
+
+	for _, record := range records {
+		if len(record) != 4 {
+			return nil, fmt.Errorf("record length not 4: %v", record)
+		}
+		from, err := NewStationID(record[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := NewStationID(record[1])
+		if err != nil {
+			return nil, err
+		}
+		minutes, err := strconv.Atoi(record[3])
+		if err != nil {
+			return nil, err
+		}
+		if minutes <= 0 {
+			return nil, fmt.Errorf("minutes not positive: %v", record)
+		}
+		final = append(final, stationPairTime{
+			from:    from,
+			to:      to,
+			period:  record[2],
+			minutes: Weight(minutes),
+		})
+	}
+
+	return final, nil
+}
+
+// validateSegmentTimes checks that every running time belongs to a known segment.
+func validateSegmentTimes(segments []Segment, times []SegmentTime) error {
+	known := make(map[Segment]bool)
+	for _, seg := range segments {
+		known[seg] = true
+		known[Segment{from: seg.to, to: seg.from}] = true
+	}
+	for _, st := range times {
+		if !known[st.segment] {
+			return fmt.Errorf("running time for unknown segment %s", st.segment)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSegments(t *testing.T) {
+	fileContent := "From,To\n" +
+		"CG0,CG1\n" +
+		"cg1,CG2\n"
+	expected := []Segment{
+		Segment{from: StationID{line: "CG", number: 0}, to: StationID{line: "CG", number: 1}},
+		Segment{from: StationID{line: "CG", number: 1}, to: StationID{line: "CG", number: 2}},
+	}
+	actual, err := ReadSegments(strings.NewReader(fileContent))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestReadSegmentsError(t *testing.T) {
+	for _, fileContent := range []string{
+		"",
+		"From,To\nCG0\n",
+		"From,To\nCG0,???\n",
+		"From,To\nCG0,EW4\n",
+		"From,To\nCG0,CG0\n",
+	} {
+		_, err := ReadSegments(strings.NewReader(fileContent))
+		if err == nil {
+			t.Errorf("expect error for input: %q", fileContent)
+		}
+	}
+}
+
+func TestValidateSegments(t *testing.T) {
+	stations := []Station{
+		Station{id: StationID{line: "CG", number: 0}, name: "Tanah Merah"},
+		Station{id: StationID{line: "CG", number: 1}, name: "Expo"},
+	}
+	valid := []Segment{
+		Segment{from: StationID{line: "CG", number: 0}, to: StationID{line: "CG", number: 1}},
+	}
+	if err := validateSegments(stations, valid); err != nil {
+		t.Errorf("not expect error: %s", err)
+	}
+	invalid := []Segment{
+		Segment{from: StationID{line: "CG", number: 1}, to: StationID{line: "CG", number: 2}},
+	}
+	if err := validateSegments(stations, invalid); err == nil {
+		t.Errorf("expect error on unknown station")
+	}
+}
+
+func TestReadSegmentTimes(t *testing.T) {
+	fileContent := "From,To,Period,Minutes\n" +
+		"NS27,NS28,,4\n" +
+		"NS27,NS28,peak,5\n"
+	expected := []SegmentTime{
+		SegmentTime{
+			segment: Segment{from: StationID{line: "NS", number: 27}, to: StationID{line: "NS", number: 28}},
+			minutes: 4,
+		},
+		SegmentTime{
+			segment: Segment{from: StationID{line: "NS", number: 27}, to: StationID{line: "NS", number: 28}},
+			period:  periodPeak,
+			minutes: 5,
+		},
+	}
+	actual, err := ReadSegmentTimes(strings.NewReader(fileContent))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestReadSegmentTimesError(t *testing.T) {
+	for _, fileContent := range []string{
+		"",
+		"From,To,Period,Minutes\nNS27,NS28,4\n",
+		"From,To,Period,Minutes\nNS27,NS28,,four\n",
+		"From,To,Period,Minutes\nNS27,NS28,,0\n",
+	} {
+		_, err := ReadSegmentTimes(strings.NewReader(fileContent))
+		if err == nil {
+			t.Errorf("expect error for input: %q", fileContent)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// lastTrainWarningMinutes is how close to the last train a boarding has to be for the
+// route to warn about depending on it
+const lastTrainWarningMinutes = 15
+
+// ServiceHours are the departure times of the first and the last train of a line, or
+// those from a Station of the line, or those from the Station toward an adjacent one.
+// The last train departs after midnight if its time is before the first train.
+type ServiceHours struct {
+	line    string
+	station StationID // zero for all Stations of the line
+	toward  StationID // zero for both directions
+	first   int       // minutes of the day
+	last    int       // minutes since midnight of the day of the first train
+}
+
+// serviceKey identifies ServiceHours by the line, Station and direction, where the zero
+// StationIDs stand for all Stations and both directions
+type serviceKey struct {
+	line    string
+	station StationID
+	toward  StationID
+}
+
+// key returns the serviceKey of the ServiceHours
+func (h ServiceHours) key() serviceKey {
+	return serviceKey{line: h.line, station: h.station, toward: h.toward}
+}
+
+// ReadServiceHours reads the first and last train times from the given io.Reader, where
+// the Station and Toward are optional.
+// It assumes the format being:
+/*
+Line,Station,Toward,First Train,Last Train
+NS,,,05:30,00:30
+NS,NS1,,05:25,23:50
+NS,NS1,NS2,05:25,00:10
+*/
+func ReadServiceHours(r io.Reader) ([]ServiceHours, error) {
+	csvReader := csv.NewReader(r)
+
+	// skip header row
+	_, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	final := []ServiceHours{}
+	seen := make(map[serviceKey]bool)
+
+	for _, record := range records {
+		if len(record) != 5 {
+			return nil, fmt.Errorf("record length not 5: %v", record)
+		}
+		hours := ServiceHours{line: strings.ToUpper(record[0])}
+		if len(hours.line) != 2 {
+			return nil, fmt.Errorf("invalid line %s", record[0])
+		}
+		if record[1] != "" {
+			if hours.station, err = NewStationID(record[1]); err != nil {
+				return nil, err
+			}
+			if hours.station.line != hours.line {
+				return nil, fmt.Errorf("station %s not on line %s", hours.station, hours.line)
+			}
+		}
+		if record[2] != "" {
+			if record[1] == "" {
+				return nil, fmt.Errorf("direction without station: %v", record)
+			}
+			if hours.toward, err = NewStationID(record[2]); err != nil {
+				return nil, err
+			}
+			if hours.toward.line != hours.line {
+				return nil, fmt.Errorf("station %s not on line %s", hours.toward, hours.line)
+			}
+		}
+		if hours.first, err = parseScheduleClock(record[3]); err != nil || hours.first == minutesPerDay {
+			return nil, fmt.Errorf("invalid first train %q", record[3])
+		}
+		if hours.last, err = parseScheduleClock(record[4]); err != nil || hours.last == minutesPerDay {
+			return nil, fmt.Errorf("invalid last train %q", record[4])
+		}
+		if hours.last < hours.first {
+			hours.last += minutesPerDay
+		}
+		if seen[hours.key()] {
+			return nil, fmt.Errorf("duplicate service hours: %v", record)
+		}
+		seen[hours.key()] = true
+		final = append(final, hours)
+	}
+
+	return final, nil
+}
+
+// validateServiceHours checks that the service hours of every Station belong to a Station
+// in the station map, and those of every direction toward an adjacent Station by Segments.
+func validateServiceHours(stations []Station, segments []Segment, hours []ServiceHours) error {
+	known := make(map[StationID]bool)
+	for _, s := range stations {
+		known[s.id] = true
+	}
+	adjacent := make(map[Segment]bool)
+	for _, seg := range segments {
+		adjacent[seg] = true
+		adjacent[Segment{from: seg.to, to: seg.from}] = true
+	}
+	for _, h := range hours {
+		if h.station != (StationID{}) && !known[h.station] {
+			return fmt.Errorf("service hours for unknown station %s", h.station)
+		}
+		if h.toward != (StationID{}) && !adjacent[Segment{from: h.station, to: h.toward}] {
+			return fmt.Errorf("service hours for unknown segment %s-%s", h.station, h.toward)
+		}
+	}
+	return nil
+}
+
+// serviceTimetable holds the ServiceHours by their serviceKey
+type serviceTimetable map[serviceKey]ServiceHours
+
+// newServiceTimetable is a helper function to make the serviceTimetable of ServiceHours
+func newServiceTimetable(hours []ServiceHours) serviceTimetable {
+	timetable := make(serviceTimetable)
+	for _, h := range hours {
+		timetable[h.key()] = h
+	}
+	return timetable
+}
+
+// hoursOf returns the ServiceHours of trains from one Station toward the adjacent one on
+// the same line, preferring those of the direction over those of the Station over those of
+// the line, and false if the line runs at all hours
+func (timetable serviceTimetable) hoursOf(from, to StationID) (ServiceHours, bool) {
+	for _, key := range []serviceKey{
+		{line: from.line, station: from, toward: to},
+		{line: from.line, station: from},
+		{line: from.line},
+	} {
+		if h, ok := timetable[key]; ok {
+			return h, true
+		}
+	}
+	return ServiceHours{}, false
+}
+
+// inService checks if a train departs at the given time, between the first train of the
+// day and the last train, which may be after midnight of the previous day
+func (h ServiceHours) inService(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	return m >= h.first && m <= h.last || m+minutesPerDay <= h.last
+}
+
+// lastTrain returns the departure time of the last train for a boarding in service at the
+// given time
+func (h ServiceHours) lastTrain(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if t.Hour()*60+t.Minute() < h.first {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day.Add(time.Duration(h.last) * time.Minute)
+}
+
+// intervals returns the minutes of the day in service from the start until before the end,
+// split at midnight
+func (h ServiceHours) intervals() [][2]int {
+	if h.last < minutesPerDay {
+		return [][2]int{{h.first, h.last + 1}}
+	}
+	return [][2]int{{0, h.last - minutesPerDay + 1}, {h.first, minutesPerDay}}
+}
+
+// inServiceFrom is a helper function to check if a train of the line leaves the Station
+// toward any adjacent Station of the cached Graph at the given time
+func (c cachedGraph) inServiceFrom(id StationID, t time.Time) bool {
+	for v := range c.graph.Edges[id] {
+		if next := v.(StationID); next.line == id.line {
+			if h, ok := c.services.hoursOf(id, next); !ok || h.inService(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lastTrainWarnings is a helper function to warn about the boardings of the Path
+// departing at the given time, ie. at the source and after each interchange, which are
+// within lastTrainWarningMinutes of the last train. Like timeDependentWeight, a boarding
+// after an interchange is checked when the walk to the platform ends.
+func (c cachedGraph) lastTrainWarnings(p Path, departure time.Time) []string {
+	weight := c.pathWeight(p, departure)
+	var warnings []string
+	// platform is the minutes of reaching the platform of the next boarding
+	var at, platform Weight
+	for i := 1; i < len(p.Stops); i++ {
+		from, to := p.Stops[i-1].(Station), p.Stops[i].(Station)
+		if from.id.line != to.id.line {
+			period := c.schedule.travelPeriod(departure.Add(time.Duration(at)*time.Minute), c.calendar)
+			platform = at + c.travelCosts[period].Interchange(from.id, to.id)
+		} else if i == 1 || p.Stops[i-2].(Station).id.line != from.id.line {
+			t := departure.Add(time.Duration(platform) * time.Minute)
+			if h, ok := c.services.hoursOf(from.id, to.id); ok && h.inService(t) {
+				if last := h.lastTrain(t); last.Sub(t) < lastTrainWarningMinutes*time.Minute {
+					warnings = append(warnings, fmt.Sprintf("Catch the last %s line train from %s toward %s at %s", from.id.line, from.name, to.name, last.Format("15:04")))
+				}
+			}
+		}
+		w, _ := weight(from.id, to.id, 0, at)
+		at += w
+	}
+	return warnings
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadServiceHours(t *testing.T) {
+	fileContent := "Line,Station,Toward,First Train,Last Train\nNS,,,05:30,00:30\nns,NS1,,05:25,23:50\nNS,NS1,NS2,05:25,00:10\n"
+	actual, err := ReadServiceHours(strings.NewReader(fileContent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ServiceHours{
+		ServiceHours{line: "NS", first: 330, last: 1470},
+		ServiceHours{line: "NS", station: StationID{"NS", 1}, first: 325, last: 1430},
+		ServiceHours{line: "NS", station: StationID{"NS", 1}, toward: StationID{"NS", 2}, first: 325, last: 1450},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestReadServiceHoursError(t *testing.T) {
+	for _, fileContent := range []string{
+		"",
+		"Line,Station,Toward,First Train,Last Train\nNS,,05:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNSL,,,05:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,EW1,,05:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,,NS2,05:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,NS1,EW2,05:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,,,5:30,00:30\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,,,05:30,24:00\n",
+		"Line,Station,Toward,First Train,Last Train\nNS,,,05:30,00:30\nNS,,,05:40,00:30\n",
+	} {
+		_, err := ReadServiceHours(strings.NewReader(fileContent))
+		if err == nil {
+			t.Errorf("expect error for input: %q", fileContent)
+		}
+	}
+}
+
+func TestValidateServiceHours(t *testing.T) {
+	stations := []Station{
+		Station{id: StationID{"NS", 1}, name: "Jurong East"},
+		Station{id: StationID{"NS", 2}, name: "Bukit Batok"},
+	}
+	segments := []Segment{Segment{from: StationID{"NS", 1}, to: StationID{"NS", 2}}}
+	if err := validateServiceHours(stations, segments, []ServiceHours{
+		ServiceHours{line: "NS"},
+		ServiceHours{line: "NS", station: StationID{"NS", 2}, toward: StationID{"NS", 1}},
+	}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := validateServiceHours(stations, segments, []ServiceHours{ServiceHours{line: "NS", station: StationID{"NS", 3}}}); err == nil {
+		t.Errorf("expect error on service hours of unknown station")
+	}
+	if err := validateServiceHours(stations, segments, []ServiceHours{ServiceHours{line: "NS", station: StationID{"NS", 1}, toward: StationID{"NS", 1}}}); err == nil {
+		t.Errorf("expect error on service hours of unknown segment")
+	}
+}
+
+func TestServiceHours(t *testing.T) {
+	hours := ServiceHours{line: "NS", first: 330, last: 1470}
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time      string
+		inService bool
+		lastTrain string
+	}{
+		{"2020-11-09T05:29", false, ""},
+		{"2020-11-09T05:30", true, "2020-11-10T00:30"},
+		{"2020-11-09T23:59", true, "2020-11-10T00:30"},
+		{"2020-11-10T00:30", true, "2020-11-10T00:30"},
+		{"2020-11-10T00:31", false, ""},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		if actual := hours.inService(travelTime); actual != testCase.inService {
+			t.Errorf("%s expect in service: %v, actual: %v", testCase.time, testCase.inService, actual)
+		}
+		if !testCase.inService {
+			continue
+		}
+		if actual := hours.lastTrain(travelTime).Format(timeLayout); actual != testCase.lastTrain {
+			t.Errorf("%s expect last train: %s, actual: %s", testCase.time, testCase.lastTrain, actual)
+		}
+	}
+
+	if actual := hours.intervals(); !reflect.DeepEqual([][2]int{{0, 31}, {330, 1440}}, actual) {
+		t.Errorf("unexpected intervals: %v", actual)
+	}
+	windows := clipGTFSWindows([]gtfsWindow{{period: periodNight, start: 0, end: 6 * 3600}}, hours)
+	if !reflect.DeepEqual([]gtfsWindow{{periodNight, 0, 31 * 60}, {periodNight, 330 * 60, 6 * 3600}}, windows) {
+		t.Errorf("unexpected clipped windows: %v", windows)
+	}
+}
+
+func TestNavigateByServiceHours(t *testing.T) {
+	n, err := NewNavigator(WithReaders(ReaderSource{
+		Stations:     strings.NewReader(testStationMap + "EW23,Clementi,12 March 1988\n"),
+		Segments:     strings.NewReader(testLineSegments + "EW23,EW24\n"),
+		ServiceHours: strings.NewReader("Line,Station,Toward,First Train,Last Train\nNS,,,05:30,00:30\nEW,,,05:30,23:30\nEW,EW24,EW23,05:30,23:45\n"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeLayout := "2006-01-02T15:04"
+	for _, testCase := range []struct {
+		time             string
+		expectError      bool
+		expectedWarnings []string
+	}{
+		{"2020-11-09T05:00", true, nil},
+		{"2020-11-09T22:00", false, nil},
+		{"2020-11-09T23:15", false, []string{"Catch the last EW line train from Jurong East toward Clementi at 23:45"}},
+		{"2020-11-09T23:40", true, nil},
+	} {
+		travelTime, _ := time.ParseInLocation(timeLayout, testCase.time, networkLocation)
+		routes, err := n.NavigateByTime("Bukit Batok", "Clementi", travelTime, NavigateOptions{})
+		if testCase.expectError {
+			if err == nil {
+				t.Errorf("%s expect error", testCase.time)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", testCase.time, err)
+			continue
+		}
+		if !reflect.DeepEqual(testCase.expectedWarnings, routes[0].Warnings) {
+			t.Errorf("%s expected warnings: %v, actual: %v", testCase.time, testCase.expectedWarnings, routes[0].Warnings)
+		}
+	}
+}
+
+func TestLastTrainWarningsOnBoard(t *testing.T) {
+	travelTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-10T00:20", networkLocation)
+	routes, err := defaultNavigator().NavigateByTime("Jurong East", "Tanah Merah", travelTime, NavigateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Catch the last EW line train from Jurong East toward Clementi at 00:30"}
+	if !reflect.DeepEqual(expected, routes[0].Warnings) {
+		t.Errorf("expected warnings: %v, actual: %v", expected, routes[0].Warnings)
+	}
+}
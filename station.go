@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -92,7 +91,7 @@ func ReadStations(r io.Reader) ([]Station, error) {
 		if err != nil {
 			return nil, err
 		}
-		openingDate, err := time.Parse(openingDateFormat, record[2])
+		openingDate, err := time.ParseInLocation(openingDateFormat, record[2], networkLocation)
 		if err != nil {
 			return nil, err
 		}
@@ -106,24 +105,11 @@ func ReadStations(r io.Reader) ([]Station, error) {
 	return final, nil
 }
 
-// a helper function to load all stations from csv file
-func loadAllStations() []Station {
-	csvFile, err := os.Open("./data/StationMap.csv")
-	if err != nil {
-		panic(err)
-	}
-	defer csvFile.Close()
-
-	allStations, err := ReadStations(csvFile)
-	if err != nil {
-		panic(err)
-	}
-	return allStations
-}
-
 // searchStations is a helper function to retrieve StationIDs for given string,
 // returns a bool to indicate if input is StationID, and error when not found.
-func searchStations(stations []Station, input string) ([]StationID, bool, error) {
+// Station names and their aliases, keyed by normalised alias, match the input regardless
+// of case and whitespace.
+func searchStations(stations []Station, aliases map[string]string, input string) ([]StationID, bool, error) {
 	// first try search by StationID
 	id, err := NewStationID(input)
 	if err == nil {
@@ -133,10 +119,12 @@ func searchStations(stations []Station, input string) ([]StationID, bool, error)
 			}
 		}
 	}
-	// then try search by Station name
+	// then try search by Station name or alias
+	key := normalizeName(input)
+	alias, hasAlias := aliases[key]
 	result := []StationID{}
 	for _, s := range stations {
-		if s.name == input {
+		if normalizeName(s.name) == key || hasAlias && s.name == alias {
 			result = append(result, s.id)
 		}
 	}
@@ -67,22 +67,22 @@ func TestReadStations(t *testing.T) {
 				Station{
 					id:          StationID{line: "EW", number: 23},
 					name:        "Clementi",
-					openingDate: time.Date(1988, 3, 12, 0, 0, 0, 0, time.UTC),
+					openingDate: time.Date(1988, 3, 12, 0, 0, 0, 0, networkLocation),
 				},
 				Station{
 					id:          StationID{line: "EW", number: 24},
 					name:        "Jurong East",
-					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, time.UTC),
+					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, networkLocation),
 				},
 				Station{
 					id:          StationID{line: "EW", number: 25},
 					name:        "Chinese Garden",
-					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, time.UTC),
+					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, networkLocation),
 				},
 				Station{
 					id:          StationID{line: "EW", number: 26},
 					name:        "Lakeside",
-					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, time.UTC),
+					openingDate: time.Date(1988, 11, 5, 0, 0, 0, 0, networkLocation),
 				},
 			},
 		},
@@ -140,7 +140,7 @@ func TestSearchStations(t *testing.T) {
 			expectIsID: false,
 		},
 	} {
-		actual, isID, err := searchStations(stations, testCase.input)
+		actual, isID, err := searchStations(stations, nil, testCase.input)
 		if testCase.expectError {
 			// test error case
 			if err == nil {
@@ -1,30 +1,43 @@
 package main
 
-import "time"
+import (
+	"time"
+	// embed the time zone database for images without one, eg. the scratch Docker image
+	_ "time/tzdata"
+)
 
-// Peak hours (6am-9am and 6pm-9pm on Mon-Fri)
-// Night hours (10pm-6am on Mon-Sun)
-// Non-Peak hours (all other times)
-func isPeakHours(t time.Time) bool {
-	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
-		return false
-	}
-	h := t.Hour()
-	return h >= 6 && h < 9 || h >= 18 && h < 21
-}
+// names of the travel periods of the default Schedule, see ./data/Schedule.json
+const (
+	periodPeak    = "peak"
+	periodNight   = "night"
+	periodNonPeak = "nonpeak"
+	periodHoliday = "holiday"
+)
+
+// localTimeFormat is the format of the time of travel without an offset, which is in
+// networkLocation
+const localTimeFormat = "2006-01-02T15:04"
 
-func isNightHours(t time.Time) bool {
-	h := t.Hour()
-	return h >= 22 || h < 6
+// networkLocation is the time zone of the network, where the travel periods, service hours
+// and opening dates are local times
+var networkLocation = mustLoadLocation("Asia/Singapore")
+
+// mustLoadLocation is a helper function to load the time zone by name from the embedded
+// time zone database, which panics as the name is known
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
 }
 
-// check if given MRT line stops operation at night hours
-func stopAtNight(line string) bool {
-	lines := []string{"DT", "CG", "CE"}
-	for _, stoppedLine := range lines {
-		if line == stoppedLine {
-			return true
-		}
+// parseTravelTime parses the time of travel in RFC 3339 format with an offset, eg.
+// "2020-11-09T08:00:00+08:00", or in local time of the network, eg. "2020-11-09T08:00",
+// and returns it in networkLocation.
+func parseTravelTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(networkLocation), nil
 	}
-	return false
+	return time.ParseInLocation(localTimeFormat, s, networkLocation)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -9,6 +10,7 @@ import (
 // Night hours (10pm-6am on Mon-Sun)
 // Non-Peak hours (all other times)
 func TestTravelPeriod(t *testing.T) {
+	schedule := defaultSchedule()
 	peak := "peak"
 	night := "night"
 	nonpeak := "nonpeak"
@@ -72,21 +74,51 @@ func TestTravelPeriod(t *testing.T) {
 		{t: sat + "22:01", h: night},
 		{t: sun + "22:01", h: night},
 	} {
-		p, err := time.Parse(timeLayout, testCase.t)
+		p, err := time.ParseInLocation(timeLayout, testCase.t, networkLocation)
 		if err != nil {
 			t.Error(err)
 		}
-		var actual string
-		switch {
-		case isPeakHours(p):
-			actual = peak
-		case isNightHours(p):
-			actual = night
-		default:
-			actual = nonpeak
-		}
-		if actual != testCase.h {
+		if actual := schedule.travelPeriod(p, nil); actual != testCase.h {
 			t.Errorf("%s %s expected: %s, actual: %s", p.Weekday(), testCase.t, testCase.h, actual)
 		}
 	}
 }
+
+func TestParseTravelTime(t *testing.T) {
+	expected := time.Date(2020, 11, 9, 8, 0, 0, 0, networkLocation)
+	for _, s := range []string{
+		"2020-11-09T08:00",
+		"2020-11-09T08:00:00+08:00",
+		"2020-11-09T00:00:00Z",
+		"2020-11-08T16:00:00-08:00",
+	} {
+		actual, err := parseTravelTime(s)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", s, err)
+			continue
+		}
+		if !actual.Equal(expected) || actual.Location() != networkLocation {
+			t.Errorf("%s expected: %s, actual: %s", s, expected, actual)
+		}
+	}
+	for _, s := range []string{"", "2020-11-09 08:00", "2020-11-09T08:00:00", "09/11/2020 08:00"} {
+		if _, err := parseTravelTime(s); err == nil {
+			t.Errorf("expect error for input: %q", s)
+		}
+	}
+}
+
+func TestNavigateByTimeInNetworkLocation(t *testing.T) {
+	n := defaultNavigator()
+	local, _ := parseTravelTime("2020-11-09T08:00")
+	// 8am in Singapore is peak hours, while midnight in UTC is night hours
+	for _, travelTime := range []time.Time{local, local.UTC()} {
+		routes, err := n.NavigateByTime("Bukit Batok", "Clementi", travelTime, NavigateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(routes[0].Periods, ",") != periodPeak {
+			t.Errorf("%s expected periods: %s, actual: %v", travelTime, periodPeak, routes[0].Periods)
+		}
+	}
+}
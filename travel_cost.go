@@ -1,78 +1,108 @@
 package main
 
-import (
-	"time"
-)
-
-// TravelCost is an interface type for getting cost of travel between Stations
+// TravelCost is an interface type for getting cost of travel between Stations, where
+// the cost of boarding a train is the expected wait for it on the platform, at the source
+// and after each interchange, apart from the cost of riding on line
 type TravelCost interface {
-	Interchange() Weight
-	OnLine(line string) Weight
+	Interchange(from, to StationID) Weight
+	OnLine(from, to StationID) Weight
+	Boarding(at StationID) Weight
 }
 
 // TravelCostByStop gives cost 1 for both interchange and travel on line
 type TravelCostByStop struct{}
 
 // Interchange implements TravelCost interface
-func (c TravelCostByStop) Interchange() Weight { return 1 }
+func (c TravelCostByStop) Interchange(_, _ StationID) Weight { return 1 }
 
 // OnLine implements TravelCost interface
-func (c TravelCostByStop) OnLine(_ string) Weight { return 1 }
+func (c TravelCostByStop) OnLine(_, _ StationID) Weight { return 1 }
+
+// Boarding implements TravelCost interface
+func (c TravelCostByStop) Boarding(_ StationID) Weight { return 0 }
 
-// TravelCostByTime contains costs for interchange and travel on line
+// TravelCostByTime contains costs for interchange, travel on line and boarding by line
 type TravelCostByTime struct {
-	interchange Weight
-	lines       map[string]Weight
-	lineDefault Weight
+	interchange     Weight
+	lines           map[string]Weight
+	lineDefault     Weight
+	boarding        map[string]Weight
+	boardingDefault Weight
 }
 
 // Interchange implements TravelCost interface
-func (c TravelCostByTime) Interchange() Weight {
+func (c TravelCostByTime) Interchange(_, _ StationID) Weight {
 	return c.interchange
 }
 
 // OnLine implements TravelCost interface
-func (c TravelCostByTime) OnLine(line string) Weight {
-	if w, ok := c.lines[line]; ok {
+func (c TravelCostByTime) OnLine(from, _ StationID) Weight {
+	if w, ok := c.lines[from.line]; ok {
 		return w
 	}
 	return c.lineDefault
 }
 
-var travelCostPeakHours = TravelCostByTime{
-	interchange: 15,
-	lines: map[string]Weight{
-		"NS": 12,
-		"NE": 12,
-	},
-	lineDefault: 10,
+// Boarding implements TravelCost interface
+func (c TravelCostByTime) Boarding(at StationID) Weight {
+	if w, ok := c.boarding[at.line]; ok {
+		return w
+	}
+	return c.boardingDefault
 }
 
-var travelCostNightHours = TravelCostByTime{
-	interchange: 10,
-	lines: map[string]Weight{
-		"TE": 8,
-	},
-	lineDefault: 10,
+// TravelCostBySegment contains running times of individual segments and walking times
+// of individual interchanges, and falls back to another TravelCost for those without
+// a known time
+type TravelCostBySegment struct {
+	segments     map[Segment]Weight
+	interchanges map[[2]StationID]Weight
+	fallback     TravelCost
 }
 
-var travelCostNonPeakHours = TravelCostByTime{
-	interchange: 10,
-	lines: map[string]Weight{
-		"DT": 8,
-		"TE": 8,
-	},
-	lineDefault: 10,
+// newTravelCostBySegment picks the segment and interchange times applicable to the given
+// period, where times of the exact period take precedence over those for all periods
+func newTravelCostBySegment(segmentTimes []SegmentTime, interchangeTimes []InterchangeTime, period string, fallback TravelCost) TravelCostBySegment {
+	segments := make(map[Segment]Weight)
+	interchanges := make(map[[2]StationID]Weight)
+	for _, p := range []string{"", period} {
+		for _, st := range segmentTimes {
+			if st.period == p {
+				segments[st.segment] = st.minutes
+			}
+		}
+		for _, it := range interchangeTimes {
+			if it.period == p {
+				interchanges[[2]StationID{it.from, it.to}] = it.minutes
+			}
+		}
+	}
+	return TravelCostBySegment{segments: segments, interchanges: interchanges, fallback: fallback}
+}
+
+// Interchange implements TravelCost interface
+func (c TravelCostBySegment) Interchange(from, to StationID) Weight {
+	if w, ok := c.interchanges[[2]StationID{from, to}]; ok {
+		return w
+	}
+	if w, ok := c.interchanges[[2]StationID{to, from}]; ok {
+		return w
+	}
+	return c.fallback.Interchange(from, to)
 }
 
-// getTravelCostByTime is a helper function to get travel cost based on time period
-func getTravelCostByTime(t time.Time) TravelCost {
-	switch {
-	case isPeakHours(t):
-		return travelCostPeakHours
-	case isNightHours(t):
-		return travelCostNightHours
-	default:
-		return travelCostNonPeakHours
+// OnLine implements TravelCost interface
+func (c TravelCostBySegment) OnLine(from, to StationID) Weight {
+	if w, ok := c.segments[Segment{from: from, to: to}]; ok {
+		return w
 	}
+	if w, ok := c.segments[Segment{from: to, to: from}]; ok {
+		return w
+	}
+	return c.fallback.OnLine(from, to)
+}
+
+// Boarding implements TravelCost interface
+func (c TravelCostBySegment) Boarding(at StationID) Weight {
+	return c.fallback.Boarding(at)
 }
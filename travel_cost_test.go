@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestTravelCostBySegment(t *testing.T) {
+	ns27 := StationID{line: "NS", number: 27}
+	ns28 := StationID{line: "NS", number: 28}
+	ew1 := StationID{line: "EW", number: 1}
+	ew2 := StationID{line: "EW", number: 2}
+	times := []SegmentTime{
+		SegmentTime{segment: Segment{from: ns27, to: ns28}, minutes: 4},
+		SegmentTime{segment: Segment{from: ns27, to: ns28}, period: periodPeak, minutes: 5},
+	}
+	fallback := TravelCostByTime{interchange: 15, lineDefault: 10}
+
+	for _, testCase := range []struct {
+		period   string
+		from     StationID
+		to       StationID
+		expected Weight
+	}{
+		{period: periodNonPeak, from: ns27, to: ns28, expected: 4},
+		{period: periodNonPeak, from: ns28, to: ns27, expected: 4},
+		{period: periodPeak, from: ns27, to: ns28, expected: 5},
+		{period: periodPeak, from: ew1, to: ew2, expected: 10},
+	} {
+		cost := newTravelCostBySegment(times, nil, testCase.period, fallback)
+		if actual := cost.OnLine(testCase.from, testCase.to); actual != testCase.expected {
+			t.Errorf("%s %s-%s expected: %d, actual: %d", testCase.period, testCase.from, testCase.to, testCase.expected, actual)
+		}
+	}
+}
+
+func TestTravelCostBySegmentInterchange(t *testing.T) {
+	ns24 := StationID{line: "NS", number: 24}
+	ne6 := StationID{line: "NE", number: 6}
+	cc1 := StationID{line: "CC", number: 1}
+	times := []InterchangeTime{
+		InterchangeTime{from: ns24, to: ne6, minutes: 6},
+		InterchangeTime{from: ns24, to: ne6, period: periodPeak, minutes: 9},
+	}
+	fallback := TravelCostByTime{interchange: 15, lineDefault: 10}
+
+	for _, testCase := range []struct {
+		period   string
+		from     StationID
+		to       StationID
+		expected Weight
+	}{
+		{period: periodNonPeak, from: ns24, to: ne6, expected: 6},
+		{period: periodNonPeak, from: ne6, to: ns24, expected: 6},
+		{period: periodPeak, from: ns24, to: ne6, expected: 9},
+		{period: periodPeak, from: ns24, to: cc1, expected: 15},
+	} {
+		cost := newTravelCostBySegment(nil, times, testCase.period, fallback)
+		if actual := cost.Interchange(testCase.from, testCase.to); actual != testCase.expected {
+			t.Errorf("%s %s-%s expected: %d, actual: %d", testCase.period, testCase.from, testCase.to, testCase.expected, actual)
+		}
+	}
+}
+
+func TestTravelCostBoarding(t *testing.T) {
+	ns1 := StationID{line: "NS", number: 1}
+	ew1 := StationID{line: "EW", number: 1}
+	fallback := TravelCostByTime{interchange: 15, lineDefault: 10, boarding: map[string]Weight{"NS": 2}, boardingDefault: 4}
+
+	for _, testCase := range []struct {
+		cost     TravelCost
+		at       StationID
+		expected Weight
+	}{
+		{cost: TravelCostByStop{}, at: ns1, expected: 0},
+		{cost: fallback, at: ns1, expected: 2},
+		{cost: fallback, at: ew1, expected: 4},
+		{cost: newTravelCostBySegment(nil, nil, periodPeak, fallback), at: ns1, expected: 2},
+	} {
+		if actual := testCase.cost.Boarding(testCase.at); actual != testCase.expected {
+			t.Errorf("%T %s expected: %d, actual: %d", testCase.cost, testCase.at, testCase.expected, actual)
+		}
+	}
+}
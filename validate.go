@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// severities of ValidationIssues, where errors fail the validation
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue is a problem found in the network data by Validate
+type ValidationIssue struct {
+	Severity string   `json:"severity"`
+	Check    string   `json:"check"`
+	Stations []string `json:"stations,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// ValidationReport lists the issues found by Validate with their counts by severity
+type ValidationReport struct {
+	Stations int               `json:"stations"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+}
+
+// add is a helper function to append an issue to the report and count it
+func (report *ValidationReport) add(severity, check string, stations []StationID, format string, a ...interface{}) {
+	issue := ValidationIssue{Severity: severity, Check: check, Message: fmt.Sprintf(format, a...)}
+	for _, id := range stations {
+		issue.Stations = append(issue.Stations, id.String())
+	}
+	report.Issues = append(report.Issues, issue)
+	if severity == SeverityError {
+		report.Errors++
+	} else {
+		report.Warnings++
+	}
+}
+
+// Validate loads the Network from the StationSource and checks it at the given time for
+// the following issues, where those that break navigation are errors:
+//
+// 1) load: the data fails to load (error);
+//
+// 2) reference: segments, running times, interchange times or coordinates reference
+// unknown Stations or Segments (error);
+//
+// 3) duplicate_id: a StationID is used by more than one station (error);
+//
+// 4) name_variant: station names differ only in case or whitespace, so the stations are
+// not linked as an interchange (error);
+//
+// 5) isolated_station: a Station is neither on any segment nor an interchange (error);
+//
+// 6) disconnected: the network of Stations opened by an opening date is split into
+// disconnected components, so some journeys are impossible (error);
+//
+// 7) number_gap: station numbers on a line are not consecutive (warning);
+//
+// 8) future_opening: a Station opens after the given time (warning);
+//
+// 9) holiday_coverage: public holidays are given, but none in the year of the given time,
+// so no day of the year is classified as a holiday (warning).
+func Validate(source StationSource, t time.Time) ValidationReport {
+	report := ValidationReport{Issues: []ValidationIssue{}}
+	network, err := source.Network()
+	if err != nil {
+		report.add(SeverityError, "load", nil, "%v", err)
+		return report
+	}
+	report.Stations = len(network.stations)
+	if err := network.validate(); err != nil {
+		report.add(SeverityError, "reference", nil, "%v", err)
+	}
+
+	// duplicate ids
+	byID := make(map[StationID][]Station)
+	ids := []StationID{}
+	for _, s := range network.stations {
+		if len(byID[s.id]) == 0 {
+			ids = append(ids, s.id)
+		}
+		byID[s.id] = append(byID[s.id], s)
+	}
+	for _, id := range ids {
+		if len(byID[id]) > 1 {
+			report.add(SeverityError, "duplicate_id", []StationID{id}, "station %s is defined %d times", id, len(byID[id]))
+		}
+	}
+
+	// name variants
+	byName := make(map[string][]string)
+	names := []string{}
+	for _, s := range network.stations {
+		key := strings.ToLower(strings.Join(strings.Fields(s.name), " "))
+		if len(byName[key]) == 0 {
+			names = append(names, key)
+		}
+		if !containsString(byName[key], s.name) {
+			byName[key] = append(byName[key], s.name)
+		}
+	}
+	for _, key := range names {
+		if variants := byName[key]; len(variants) > 1 {
+			stations := []StationID{}
+			for _, s := range network.stations {
+				if containsString(variants, s.name) {
+					stations = append(stations, s.id)
+				}
+			}
+			report.add(SeverityError, "name_variant", stations, "station names %q differ only in case or whitespace", variants)
+		}
+	}
+
+	// isolated stations in the whole network
+	g := buildGraph(network.stations, network.segments, TravelCostByStop{})
+	for _, id := range ids {
+		if len(g.Edges[id]) == 0 {
+			report.add(SeverityError, "isolated_station", []StationID{id}, "station %s %s is not connected to any station", id, byID[id][0].name)
+		}
+	}
+
+	// disconnected components of the network by each opening date, except those caused by
+	// isolated stations, each reported once at the first date it appears
+	reported := make(map[string]bool)
+	openingDates := []time.Time{}
+	for _, s := range network.stations {
+		openingDates = append(openingDates, s.openingDate)
+	}
+	sort.Slice(openingDates, func(i, j int) bool { return openingDates[i].Before(openingDates[j]) })
+	for i, date := range openingDates {
+		if i > 0 && date.Equal(openingDates[i-1]) {
+			continue
+		}
+		opened := []Station{}
+		for _, s := range network.stations {
+			if !date.Before(s.openingDate) && len(g.Edges[s.id]) > 0 {
+				opened = append(opened, s)
+			}
+		}
+		components := connectedComponents(buildGraph(opened, network.segments, TravelCostByStop{}), opened)
+		if len(components) < 2 {
+			continue
+		}
+		// report all but the largest component
+		stations := []StationID{}
+		for _, component := range components[1:] {
+			if key := fmt.Sprint(component); !reported[key] {
+				reported[key] = true
+				stations = append(stations, component...)
+			}
+		}
+		if len(stations) == 0 {
+			continue
+		}
+		report.add(SeverityError, "disconnected", stations, "network on %s has %d disconnected components", date.Format("2 January 2006"), len(components))
+	}
+
+	// gaps of station numbers on each line
+	numbers := make(map[string][]int)
+	lines := []string{}
+	for _, id := range ids {
+		if len(numbers[id.line]) == 0 {
+			lines = append(lines, id.line)
+		}
+		numbers[id.line] = append(numbers[id.line], id.number)
+	}
+	for _, line := range lines {
+		ns := numbers[line]
+		sort.Ints(ns)
+		for i := 1; i < len(ns); i++ {
+			if ns[i]-ns[i-1] > 1 {
+				report.add(SeverityWarning, "number_gap", []StationID{{line, ns[i-1]}, {line, ns[i]}}, "no station numbered between %s%d and %s%d", line, ns[i-1], line, ns[i])
+			}
+		}
+	}
+
+	// stations opening in future
+	for _, s := range network.stations {
+		if t.Before(s.openingDate) {
+			report.add(SeverityWarning, "future_opening", []StationID{s.id}, "station %s %s opens on %s", s.id, s.name, s.openingDate.Format("2 January 2006"))
+		}
+	}
+
+	// public holidays of the year
+	if calendar := newCalendar(network.holidays); len(calendar) > 0 && !calendar.covers(t) {
+		report.add(SeverityWarning, "holiday_coverage", nil, "no public holidays in %d", t.Year())
+	}
+
+	return report
+}
+
+// connectedComponents is a helper function to find the connected components of Stations
+// in the Graph, ordered by size descending
+func connectedComponents(g *Graph, stations []Station) [][]StationID {
+	components := [][]StationID{}
+	visited := make(map[StationID]bool)
+	for _, s := range stations {
+		if visited[s.id] {
+			continue
+		}
+		component := []StationID{}
+		visited[s.id] = true
+		queue := []StationID{s.id}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, current)
+			for v := range g.Edges[current] {
+				if next := v.(StationID); !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		sort.Slice(component, func(i, j int) bool {
+			if component[i].line != component[j].line {
+				return component[i].line < component[j].line
+			}
+			return component[i].number < component[j].number
+		})
+		components = append(components, component)
+	}
+	sort.SliceStable(components, func(i, j int) bool { return len(components[i]) > len(components[j]) })
+	return components
+}
+
+// containsString is a helper function to check if the string is in the list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
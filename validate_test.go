@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	validationTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	report := Validate(ReaderSource{
+		Stations: strings.NewReader("Station Code,Station Name,Opening Date\n" +
+			"NS1,Jurong East,10 March 1990\n" +
+			"NS2,Bukit Batok,10 March 1990\n" +
+			"NS4,Choa Chu Kang,10 March 1990\n" +
+			"NS4,Choa Chu Kang,10 March 1990\n" +
+			"EW24,jurong  east,5 November 1988\n" +
+			"EW23,Clementi,5 November 1988\n" +
+			"CC1,Dhoby Ghaut,1 January 2030\n"),
+		Segments: strings.NewReader("From,To\nNS1,NS2\nNS2,NS4\nEW24,EW23\n"),
+	}, validationTime)
+
+	type issue struct {
+		severity string
+		check    string
+		stations []string
+	}
+	actual := []issue{}
+	for _, i := range report.Issues {
+		actual = append(actual, issue{i.Severity, i.Check, i.Stations})
+	}
+	expected := []issue{
+		{SeverityError, "duplicate_id", []string{"NS4"}},
+		{SeverityError, "name_variant", []string{"NS1", "EW24"}},
+		{SeverityError, "isolated_station", []string{"CC1"}},
+		{SeverityError, "disconnected", []string{"EW23", "EW24"}},
+		{SeverityWarning, "number_gap", []string{"NS2", "NS4"}},
+		{SeverityWarning, "future_opening", []string{"CC1"}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if report.Stations != 7 || report.Errors != 4 || report.Warnings != 2 {
+		t.Errorf("expected 7 stations, 4 errors and 2 warnings, actual: %d, %d, %d", report.Stations, report.Errors, report.Warnings)
+	}
+}
+
+func TestValidateDefault(t *testing.T) {
+	validationTime, _ := time.ParseInLocation("2006-01-02T15:04", "2020-11-09T08:00", networkLocation)
+	report := Validate(defaultSource(), validationTime)
+	if report.Errors != 0 {
+		t.Errorf("expect no errors, actual: %v", report.Issues)
+	}
+}
+
+func TestValidateError(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		source StationSource
+		check  string
+	}{
+		{
+			name:   "missing segments",
+			source: ReaderSource{Stations: strings.NewReader(testStationMap)},
+			check:  "load",
+		},
+		{
+			name: "segment of unknown station",
+			source: ReaderSource{
+				Stations: strings.NewReader(testStationMap),
+				Segments: strings.NewReader("From,To\nNS1,NS2\nNS1,NS3\n"),
+			},
+			check: "reference",
+		},
+	} {
+		report := Validate(testCase.source, time.Now())
+		if report.Errors == 0 || report.Issues[0].Check != testCase.check {
+			t.Errorf("%s expect %s error, actual: %v", testCase.name, testCase.check, report.Issues)
+		}
+	}
+}